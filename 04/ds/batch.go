@@ -0,0 +1,64 @@
+package ds
+
+import "time"
+
+// NewBatchEventloop은 이벤트를 모아서 handler에 전달하는 Eventloop을 생성한다.
+// dispatcher는 첫 이벤트를 받은 뒤 maxBatch개가 모이거나 maxWait이 지나면 handler를 호출하며
+// Close 시에는 모으던 이벤트와 queue에 남은 이벤트를 모두 전달한 뒤 종료한다.
+// 이벤트 단위로 동작하는 middleware는 적용되지 않는다.
+func NewBatchEventloop[T any](dispatchCount, queueSize, maxBatch int, maxWait time.Duration, handler func([]T), opts ...EventloopOption[T]) *Eventloop[T] {
+	e := newEventloop(dispatchCount, queueSize, opts)
+	e.batchHandler = handler
+	e.maxBatch = max(maxBatch, 1)
+	e.maxWait = maxWait
+	return e
+}
+
+// collect는 first 이후의 이벤트를 maxBatch개 또는 maxWait까지 모은다.
+// 종료가 시작되거나 Pause되면 기다리지 않고 모은 만큼 반환한다.
+func (e *Eventloop[T]) collect(first Envelope[T]) []Envelope[T] {
+	batch := make([]Envelope[T], 1, e.maxBatch)
+	batch[0] = first
+	if len(batch) >= e.maxBatch {
+		return batch
+	}
+
+	timer := time.NewTimer(e.maxWait)
+	defer timer.Stop()
+	for len(batch) < e.maxBatch {
+		// paused 확인 전에 changeCh를 읽어야 Pause 알림을 놓치지 않음
+		change := *e.changeCh.Load()
+		if e.paused.Load() {
+			return batch
+		}
+
+		select {
+		case <-change:
+		case env := <-e.popCh:
+			batch = e.appendLive(batch, e.dequeued(env))
+		case <-e.notEmpty:
+			if env, ok := e.pop(); ok {
+				batch = e.appendLive(batch, e.dequeued(env))
+			}
+		case <-timer.C:
+			return batch
+		case <-e.idleCh:
+			return e.fill(batch)
+		case <-e.forceCh:
+			return batch
+		}
+	}
+	return batch
+}
+
+// fill은 queue에서 대기 없이 꺼낼 수 있는 이벤트를 maxBatch개까지 batch에 추가한다.
+func (e *Eventloop[T]) fill(batch []Envelope[T]) []Envelope[T] {
+	for len(batch) < e.maxBatch && !e.paused.Load() {
+		env, ok := e.pop()
+		if !ok {
+			return batch
+		}
+		batch = e.appendLive(batch, e.dequeued(env))
+	}
+	return batch
+}
@@ -0,0 +1,137 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchEventloopMaxBatch(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	handler := func(events []int) {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, events)
+	}
+	el := NewBatchEventloop(1, 1024, 4, time.Hour, handler)
+
+	for i := range 10 {
+		require.NoError(t, el.Send(i))
+	}
+	go el.Run()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(batches) == 2
+	}, time.Second, time.Millisecond)
+
+	// 남은 2개는 Close 시 전달되어야 함
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}}, batches)
+}
+
+func TestBatchEventloopMaxWait(t *testing.T) {
+	flushed := make(chan []int, 1)
+	handler := func(events []int) {
+		flushed <- events
+	}
+	el := NewBatchEventloop(1, 1024, 100, 10*time.Millisecond, handler)
+
+	go el.Run()
+	defer el.Close()
+
+	start := time.Now()
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(2))
+
+	select {
+	case events := <-flushed:
+		require.Equal(t, []int{1, 2}, events)
+		require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	case <-time.After(time.Second):
+		require.Fail(t, "batch is not flushed")
+	}
+}
+
+func TestBatchEventloopCloseExactlyOnce(t *testing.T) {
+	var mu sync.Mutex
+	processed := make(map[int]int)
+	handler := func(events []int) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, event := range events {
+			processed[event]++
+		}
+	}
+	el := NewBatchEventloop(4, 16, 8, time.Millisecond, handler)
+
+	go el.Run()
+
+	var wg sync.WaitGroup
+	sent := make(chan int, 1000)
+	for i := range 1000 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := el.Send(i); err == nil {
+				sent <- i
+			}
+		}(i)
+	}
+	time.Sleep(time.Millisecond)
+	el.Close()
+	wg.Wait()
+	<-el.Done()
+	close(sent)
+
+	mu.Lock()
+	defer mu.Unlock()
+	count := 0
+	for i := range sent {
+		require.Equal(t, 1, processed[i])
+		count++
+	}
+	require.Len(t, processed, count)
+}
+
+func TestBatchEventloopPause(t *testing.T) {
+	flushed := make(chan []int, 2)
+	handler := func(events []int) {
+		flushed <- events
+	}
+	el := NewBatchEventloop(1, 1024, 100, time.Hour, handler)
+	go el.Run()
+
+	require.NoError(t, el.Send(1))
+	require.Eventually(t, func() bool {
+		return el.Stats().QueueLen == 0
+	}, time.Second, time.Millisecond)
+
+	// Pause되면 모으던 batch는 바로 전달되어야 함
+	el.Pause()
+	select {
+	case events := <-flushed:
+		require.Equal(t, []int{1}, events)
+	case <-time.After(time.Second):
+		require.Fail(t, "batch is not flushed on pause")
+	}
+
+	for i := 2; i <= 10; i++ {
+		require.NoError(t, el.Send(i))
+	}
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 9, el.Stats().QueueLen)
+	require.Empty(t, flushed)
+
+	el.Resume()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10}, <-flushed)
+}
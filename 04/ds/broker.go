@@ -0,0 +1,183 @@
+package ds
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type Message[T any] struct {
+	Topic   string
+	Payload T
+}
+
+// Broker는 topic 단위로 메시지를 발행하는 프로세스 내 pub/sub broker다.
+// 구독자마다 별도의 Eventloop을 가지므로 느린 구독자가 발행자나 다른 구독자를 막지 않는다.
+// 구독 패턴은 '.'으로 구분되며 '*'는 한 단계, '#'은 마지막에만 올 수 있고 0개 이상의 단계와 일치한다.
+type Broker[T any] struct {
+	mu     sync.Mutex
+	topics *Map[string, []*Subscription[T]]
+	closed atomic.Bool
+}
+
+type Subscription[T any] struct {
+	pattern string
+	broker  *Broker[T]
+	loop    *Eventloop[Message[T]]
+	once    sync.Once
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		topics: NewMap[string, []*Subscription[T]](16),
+	}
+}
+
+// Subscribe는 pattern과 일치하는 topic의 메시지를 handler로 전달받는다.
+// 기본 overflow 정책은 OverflowDropNewest이며 발행자가 대기하지 않도록 OverflowBlock을 지정하면 ErrBlockingSubscriber를 반환한다.
+// queueSize가 1보다 작으면 ErrInvalidQueueSize를 반환한다.
+func (b *Broker[T]) Subscribe(pattern string, queueSize int, handler func(Message[T]), opts ...EventloopOption[Message[T]]) (*Subscription[T], error) {
+	if queueSize < 1 {
+		return nil, ErrInvalidQueueSize
+	}
+
+	opts = append([]EventloopOption[Message[T]]{WithOverflowPolicy[Message[T]](OverflowDropNewest)}, opts...)
+	loop := NewEventloop(1, queueSize, handler, opts...)
+	if loop.overflow == OverflowBlock {
+		return nil, ErrBlockingSubscriber
+	}
+
+	s := &Subscription[T]{
+		pattern: pattern,
+		broker:  b,
+		loop:    loop,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed.Load() {
+		return nil, ErrAlreadyClosedLoop
+	}
+
+	subs, _ := b.topics.Load(pattern)
+	b.topics.Store(pattern, append(slices.Clip(subs), s))
+	go loop.Run()
+	return s, nil
+}
+
+// Publish는 topic과 일치하는 모든 구독자에게 메시지를 보내고 전달에 성공한 구독자 수를 반환한다.
+// 구독자의 queue가 가득 찬 경우 그 구독자의 overflow 정책을 따르며 발행자는 대기하지 않는다.
+func (b *Broker[T]) Publish(topic string, payload T) (int, error) {
+	if b.closed.Load() {
+		return 0, ErrAlreadyClosedLoop
+	}
+
+	msg := Message[T]{Topic: topic, Payload: payload}
+	delivered := 0
+	b.topics.Range(func(pattern string, subs []*Subscription[T]) bool {
+		if !matchTopic(pattern, topic) {
+			return true
+		}
+		for _, s := range subs {
+			if s.loop.Send(msg) == nil {
+				delivered++
+			}
+		}
+		return true
+	})
+	return delivered, nil
+}
+
+// Close는 새 발행을 막고 모든 구독을 해제한다. 구독자의 queue에 남은 메시지는 처리된다.
+func (b *Broker[T]) Close() {
+	b.close()
+}
+
+// close는 closed를 표시한 mutex 안에서 구독을 모두 닫고 반환하므로 동시에 Subscribe한 구독을 놓치지 않는다.
+// 이미 닫혀 있으면 nil을 반환한다.
+func (b *Broker[T]) close() []*Subscription[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	var closed []*Subscription[T]
+	b.topics.Range(func(pattern string, subs []*Subscription[T]) bool {
+		for _, s := range subs {
+			s.loop.Close()
+		}
+		closed = append(closed, subs...)
+		b.topics.Delete(pattern)
+		return true
+	})
+	return closed
+}
+
+// Shutdown은 Close 후 모든 구독자가 남은 메시지를 처리할 때까지 대기한다.
+func (b *Broker[T]) Shutdown(ctx context.Context) error {
+	for _, s := range b.close() {
+		select {
+		case <-s.loop.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *Subscription[T]) Pattern() string {
+	return s.pattern
+}
+
+func (s *Subscription[T]) Stats() EventloopStats {
+	return s.loop.Stats()
+}
+
+// Done은 구독이 해제된 뒤 남은 메시지가 모두 처리되면 닫힌다.
+func (s *Subscription[T]) Done() <-chan struct{} {
+	return s.loop.Done()
+}
+
+// Unsubscribe는 구독을 해제한다. 이미 queue에 들어간 메시지는 처리된다.
+func (s *Subscription[T]) Unsubscribe() {
+	s.once.Do(func() {
+		b := s.broker
+		b.mu.Lock()
+		subs, _ := b.topics.Load(s.pattern)
+		subs = slices.DeleteFunc(slices.Clone(subs), func(sub *Subscription[T]) bool {
+			return sub == s
+		})
+		if len(subs) == 0 {
+			b.topics.Delete(s.pattern)
+		} else {
+			b.topics.Store(s.pattern, subs)
+		}
+		b.mu.Unlock()
+
+		s.loop.Close()
+	})
+}
+
+func matchTopic(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+
+	ps := strings.Split(pattern, ".")
+	ts := strings.Split(topic, ".")
+	for i, p := range ps {
+		if p == "#" && i == len(ps)-1 {
+			return true
+		}
+		if i >= len(ts) {
+			return false
+		}
+		if p != "*" && p != ts[i] {
+			return false
+		}
+	}
+	return len(ps) == len(ts)
+}
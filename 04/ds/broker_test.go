@@ -0,0 +1,164 @@
+package ds
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMatchTopic(t *testing.T) {
+	cases := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.deleted", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"*.created", "users.created", true},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.created.eu", true},
+		{"#", "anything.at.all", true},
+		{"orders.*.eu", "orders.created.us", false},
+		{"orders", "orders.created", false},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, matchTopic(c.pattern, c.topic), "%s ~ %s", c.pattern, c.topic)
+	}
+}
+
+func TestBrokerPublish(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	b := NewBroker[int]()
+	exact, all := &recorder[string]{}, &recorder[string]{}
+	_, err := b.Subscribe("orders.created", 16, func(m Message[int]) { exact.handle(m.Topic) })
+	require.NoError(t, err)
+	_, err = b.Subscribe("orders.#", 16, func(m Message[int]) { all.handle(m.Topic) })
+	require.NoError(t, err)
+
+	n, err := b.Publish("orders.created", 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = b.Publish("orders.deleted", 2)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, err = b.Publish("users.created", 3)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	require.NoError(t, b.Shutdown(context.Background()))
+	require.Equal(t, []string{"orders.created"}, exact.get())
+	require.Equal(t, []string{"orders.created", "orders.deleted"}, all.get())
+
+	_, err = b.Publish("orders.created", 4)
+	require.ErrorIs(t, err, ErrAlreadyClosedLoop)
+	_, err = b.Subscribe("orders.created", 16, func(Message[int]) {})
+	require.ErrorIs(t, err, ErrAlreadyClosedLoop)
+}
+
+func TestBrokerUnsubscribe(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	b := NewBroker[int]()
+	r := &recorder[int]{}
+	s, err := b.Subscribe("a.*", 16, func(m Message[int]) { r.handle(m.Payload) })
+	require.NoError(t, err)
+	require.Equal(t, "a.*", s.Pattern())
+
+	_, err = b.Publish("a.b", 1)
+	require.NoError(t, err)
+	s.Unsubscribe()
+	s.Unsubscribe()
+	<-s.Done()
+
+	n, err := b.Publish("a.b", 2)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Equal(t, []int{1}, r.get())
+	require.Zero(t, b.topics.Len())
+	b.Close()
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	b := NewBroker[int]()
+	block := make(chan struct{})
+	slow, err := b.Subscribe("t", 1, func(Message[int]) { <-block })
+	require.NoError(t, err)
+	fast := &recorder[int]{}
+	_, err = b.Subscribe("t", 16, func(m Message[int]) { fast.handle(m.Payload) })
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 10 {
+			_, _ = b.Publish("t", i)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher blocked by slow subscriber")
+	}
+	require.Eventually(t, func() bool {
+		return len(fast.get()) == 10
+	}, time.Second, time.Millisecond)
+	require.Positive(t, slow.Stats().Dropped)
+
+	close(block)
+	require.NoError(t, b.Shutdown(context.Background()))
+}
+
+func TestBrokerBlockingSubscriber(t *testing.T) {
+	b := NewBroker[int]()
+	_, err := b.Subscribe("t", 1, func(Message[int]) {}, WithOverflowPolicy[Message[int]](OverflowBlock))
+	require.ErrorIs(t, err, ErrBlockingSubscriber)
+	require.NoError(t, b.Shutdown(context.Background()))
+}
+
+func TestBrokerShutdownConcurrentSubscribe(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	for range 20 {
+		b := NewBroker[int]()
+		subsCh := make(chan []*Subscription[int])
+		go func() {
+			var subs []*Subscription[int]
+			for {
+				s, err := b.Subscribe("t", 1, func(Message[int]) {})
+				if err != nil {
+					subsCh <- subs
+					return
+				}
+				subs = append(subs, s)
+				runtime.Gosched()
+			}
+		}()
+
+		runtime.Gosched()
+		require.NoError(t, b.Shutdown(context.Background()))
+		// Shutdown이 반환되면 성공한 구독은 모두 종료되어 있어야 함
+		for _, s := range <-subsCh {
+			select {
+			case <-s.loop.Done():
+			default:
+				t.Fatal("subscription not shut down")
+			}
+		}
+	}
+}
+
+func TestBrokerInvalidQueueSize(t *testing.T) {
+	b := NewBroker[int]()
+	_, err := b.Subscribe("t", 0, func(Message[int]) {}, WithOverflowPolicy[Message[int]](OverflowDropOldest))
+	require.ErrorIs(t, err, ErrInvalidQueueSize)
+	require.NoError(t, b.Shutdown(context.Background()))
+}
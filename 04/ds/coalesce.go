@@ -0,0 +1,119 @@
+package ds
+
+import (
+	"sync"
+	"time"
+)
+
+// coalescer는 key별로 queue에서 대기 중인 이벤트를 하나만 유지한다.
+// Eventloop은 key의 타입을 알 수 없으므로 interface로 감싼다.
+type coalescer[T any] interface {
+	add(env *Envelope[T]) (T, bool)
+	queued(slot *coalesceSlot[T])
+	abort(slot *coalesceSlot[T])
+	take(slot *coalesceSlot[T]) (T, time.Time)
+}
+
+// coalesceSlot은 queue에 들어간 이벤트의 자리다. dispatcher가 꺼내기 전까지 같은 key의 이벤트가 합쳐진다.
+type coalesceSlot[T any] struct {
+	key      any
+	event    T
+	deadline time.Time
+	// seqs는 합쳐진 이벤트들의 WAL seq이며 slot의 이벤트가 처리되면 함께 ack된다.
+	seqs []uint64
+	// ready는 queue에 들어간 뒤에만 true이며 그 전에는 다른 이벤트를 합치지 않는다.
+	ready bool
+}
+
+type keyCoalescer[K comparable, T any] struct {
+	mu      sync.Mutex
+	key     func(T) K
+	merge   func(old, new T) T
+	pending map[K]*coalesceSlot[T]
+}
+
+// WithCoalesce는 같은 key의 이벤트가 queue에서 대기 중이면 새 이벤트를 queue에 넣지 않고 대기 중인 이벤트와 합친다.
+// merge가 nil이면 새 이벤트로 교체하며 아니면 merge(대기 중인 이벤트, 새 이벤트)의 결과로 바꾼다.
+// 합쳐진 Send는 성공으로 집계되고 Stats의 Coalesced가 증가한다.
+// 합쳐진 이벤트는 Tracer의 OnDrop에 ErrEventCoalesced와 함께 전달된다.
+// NewCaller에 사용하면 대기 중이던 요청의 Future는 ErrEventCoalesced로 끝나고 merge가 반환한 요청의 Future가 결과를 받는다.
+// dispatcher가 이벤트를 꺼낸 뒤에 들어온 같은 key의 이벤트는 다시 queue에 들어간다.
+// WithSpill로 디스크에 기록된 이벤트와 SendAfter, SendAt으로 예약한 이벤트는 합쳐지지 않는다.
+func WithCoalesce[T any, K comparable](key func(T) K, merge func(old, new T) T) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		if merge == nil {
+			merge = func(_, new T) T {
+				return new
+			}
+		}
+		e.coalesce = &keyCoalescer[K, T]{
+			key:     key,
+			merge:   merge,
+			pending: make(map[K]*coalesceSlot[T]),
+		}
+	}
+}
+
+// add는 같은 key의 이벤트가 queue에서 대기 중이면 합치고 합치기 전에 대기 중이던 이벤트와 true를 반환한다.
+// 대기 중인 이벤트가 없으면 env에 새 slot을 연결한다.
+func (c *keyCoalescer[K, T]) add(env *Envelope[T]) (T, bool) {
+	var zero T
+	k := c.key(env.event)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if slot, ok := c.pending[k]; ok {
+		if !slot.ready {
+			// 먼저 들어온 Send가 아직 queue에 넣는 중이면 합치지 않고 따로 보냄
+			return zero, false
+		}
+		replaced := slot.event
+		slot.event = c.merge(slot.event, env.event)
+		slot.deadline = env.deadline
+		if env.seq != 0 {
+			slot.seqs = append(slot.seqs, env.seq)
+		}
+		return replaced, true
+	}
+
+	slot := &coalesceSlot[T]{key: k, event: env.event, deadline: env.deadline}
+	c.pending[k] = slot
+	env.slot = slot
+	return zero, false
+}
+
+func (c *keyCoalescer[K, T]) queued(slot *coalesceSlot[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slot.ready = true
+}
+
+// abort는 queue에 넣지 못한 slot을 제거한다.
+func (c *keyCoalescer[K, T]) abort(slot *coalesceSlot[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(slot)
+}
+
+// take는 dispatcher가 꺼낸 slot을 제거하고 합쳐진 이벤트와 마지막 이벤트의 deadline을 반환한다.
+func (c *keyCoalescer[K, T]) take(slot *coalesceSlot[T]) (T, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(slot)
+	return slot.event, slot.deadline
+}
+
+func (c *keyCoalescer[K, T]) remove(slot *coalesceSlot[T]) {
+	k := slot.key.(K)
+	if c.pending[k] == slot {
+		delete(c.pending, k)
+	}
+}
+
+// resolve는 queue에서 꺼낸 envelope에 합쳐진 이벤트를 반영한다.
+func (e *Eventloop[T]) resolve(env Envelope[T]) Envelope[T] {
+	if env.slot != nil {
+		env.event, env.deadline = e.coalesce.take(env.slot)
+	}
+	return env
+}
@@ -0,0 +1,157 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type update struct {
+	Key   string
+	Value int
+}
+
+func TestCoalesce(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	r := &recorder[update]{}
+	el := NewEventloop(1, 16, func(u update) {
+		if u.Key == "block" {
+			<-block
+		}
+		r.handle(u)
+	}, WithCoalesce(func(u update) string { return u.Key }, nil))
+	go el.Run()
+
+	require.NoError(t, el.Send(update{Key: "block"}))
+	require.Eventually(t, func() bool {
+		return el.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+
+	for i := range 10 {
+		require.NoError(t, el.Send(update{Key: "a", Value: i}))
+		require.NoError(t, el.Send(update{Key: "b", Value: i}))
+	}
+	stats := el.Stats()
+	require.Equal(t, 2, stats.QueueLen)
+	require.Equal(t, uint64(18), stats.Coalesced)
+	require.Equal(t, uint64(21), stats.Sent)
+
+	close(block)
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []update{{Key: "block"}, {Key: "a", Value: 9}, {Key: "b", Value: 9}}, r.get())
+}
+
+func TestCoalesceMerge(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[update]{}
+	el := NewEventloop(1, 16, r.handle, WithCoalesce(func(u update) string { return u.Key }, func(old, new update) update {
+		new.Value += old.Value
+		return new
+	}))
+	for i := range 5 {
+		require.NoError(t, el.Send(update{Key: "a", Value: i}))
+	}
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []update{{Key: "a", Value: 10}}, r.get())
+}
+
+func TestCoalesceAfterDequeue(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	mu := sync.Mutex{}
+	var got []int
+	started := make(chan struct{}, 1)
+	block := make(chan struct{})
+	el := NewEventloop(1, 4, func(v int) {
+		mu.Lock()
+		got = append(got, v)
+		mu.Unlock()
+		if v == 0 {
+			started <- struct{}{}
+			<-block
+		}
+	}, WithCoalesce(func(int) int { return 0 }, nil))
+	go el.Run()
+
+	require.NoError(t, el.Send(0))
+	<-started
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(2))
+	close(block)
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{0, 2}, got)
+}
+
+func TestCoalesceForceClose(t *testing.T) {
+	el := NewEventloop(1, 4, func(int) {}, WithCoalesce(func(v int) int { return v % 2 }, nil))
+	for i := range 6 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Equal(t, []int{4, 5}, el.ForceClose())
+}
+
+func TestCoalesceRejected(t *testing.T) {
+	el := NewEventloop(1, 1, func(int) {},
+		WithCoalesce(func(v int) int { return v }, nil),
+		WithOverflowPolicy[int](OverflowDropNewest))
+	require.NoError(t, el.Send(1))
+	require.ErrorIs(t, el.Send(2), ErrQueueFull)
+	require.NoError(t, el.Send(1))
+
+	c := el.coalesce.(*keyCoalescer[int, int])
+	require.Len(t, c.pending, 1)
+	require.Equal(t, []int{1}, el.ForceClose())
+}
+
+func TestCoalesceWAL(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, JSONCodec[update]{}, WALConfig{})
+	require.NoError(t, err)
+
+	r := &recorder[update]{}
+	el := NewEventloop(1, 4, r.handle, WithWAL(w), WithCoalesce(func(u update) string { return u.Key }, nil))
+	for i := range 3 {
+		require.NoError(t, el.Send(update{Key: "a", Value: i}))
+	}
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.NoError(t, w.Close())
+	require.Equal(t, []update{{Key: "a", Value: 2}}, r.get())
+
+	w, err = OpenWAL(dir, JSONCodec[update]{}, WALConfig{})
+	require.NoError(t, err)
+	defer w.Close()
+	require.Empty(t, w.unacked())
+}
+
+func TestCoalesceTracer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	tracer := NewRecordingTracer[update]()
+	el := NewEventloop(1, 16, func(u update) {}, WithTracer[update](tracer),
+		WithCoalesce(func(u update) string { return u.Key }, nil))
+	for i := range 3 {
+		require.NoError(t, el.Send(update{Key: "a", Value: i}))
+	}
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	// 모든 span은 handler 종료나 OnDrop으로 끝나야 함
+	require.Equal(t, []TraceKind{TraceEnqueue, TraceDequeue, TraceHandlerStart, TraceHandlerEnd}, tracer.Span(1))
+	for _, span := range []uint64{2, 3} {
+		require.Equal(t, []TraceKind{TraceEnqueue, TraceDrop}, tracer.Span(span))
+	}
+	for _, r := range tracer.Records() {
+		if r.Kind == TraceDrop {
+			require.ErrorIs(t, r.Err, ErrEventCoalesced)
+		}
+	}
+}
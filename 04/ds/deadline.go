@@ -0,0 +1,63 @@
+package ds
+
+import (
+	"context"
+	"time"
+)
+
+// Deadliner를 구현한 이벤트는 Deadline이 반환한 시각이 지나면 handler에 전달되지 않는다.
+type Deadliner interface {
+	Deadline() (time.Time, bool)
+}
+
+// WithOnExpired는 deadline이 지나 버려지는 이벤트를 f로 전달한다.
+func WithOnExpired[T any](f func(T)) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.onExpired = f
+	}
+}
+
+// SendWithDeadline은 Send와 같지만 dispatcher가 deadline 이후에 이벤트를 꺼내면 handler를 호출하지 않는다.
+// 이벤트가 Deadliner를 구현하더라도 deadline이 우선한다.
+// WithSpill로 디스크에 기록된 이벤트는 deadline 대신 Deadliner의 값을 사용한다.
+func (e *Eventloop[T]) SendWithDeadline(event T, deadline time.Time) error {
+	env := e.wrap(context.Background(), event)
+	if !deadline.IsZero() {
+		env.deadline = deadline
+	}
+	return e.send(context.Background(), env, true, nil)
+}
+
+func deadlineOf[T any](event T) time.Time {
+	if d, ok := any(event).(Deadliner); ok {
+		if deadline, ok := d.Deadline(); ok {
+			return deadline
+		}
+	}
+	return time.Time{}
+}
+
+// expire는 deadline이 지난 이벤트를 버리고 true를 반환한다.
+func (e *Eventloop[T]) expire(env Envelope[T]) bool {
+	if env.deadline.IsZero() || time.Now().Before(env.deadline) {
+		return false
+	}
+
+	e.expired.Add(1)
+	e.ack(env)
+	if e.onExpired != nil {
+		e.onExpired(env.event)
+	}
+	if e.tracer != nil {
+		e.tracer.OnDrop(env.ctx, env.event, ErrEventExpired)
+	}
+	return true
+}
+
+// appendLive는 deadline이 지나지 않은 이벤트만 batch에 추가한다.
+func (e *Eventloop[T]) appendLive(batch []Envelope[T], env Envelope[T]) []Envelope[T] {
+	if e.expire(env) {
+		return batch
+	}
+	return append(batch, env)
+}
@@ -0,0 +1,118 @@
+package ds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type expiring struct {
+	id       int
+	deadline time.Time
+}
+
+func (e expiring) Deadline() (time.Time, bool) {
+	return e.deadline, !e.deadline.IsZero()
+}
+
+func TestSendWithDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	r := &recorder[int]{}
+	expired := &recorder[int]{}
+	tracer := NewRecordingTracer[int]()
+	el := NewEventloop(1, 8, func(event int) {
+		if event == 0 {
+			<-block
+		}
+		r.handle(event)
+	}, WithOnExpired(expired.handle), WithTracer[int](tracer))
+	go el.Run()
+
+	require.NoError(t, el.Send(0))
+	require.Eventually(t, func() bool {
+		return el.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+
+	now := time.Now()
+	require.NoError(t, el.SendWithDeadline(1, now.Add(time.Millisecond)))
+	require.NoError(t, el.SendWithDeadline(2, now.Add(time.Hour)))
+	require.NoError(t, el.Send(3))
+	time.Sleep(5 * time.Millisecond)
+
+	close(block)
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{0, 2, 3}, r.get())
+	require.Equal(t, []int{1}, expired.get())
+
+	stats := el.Stats()
+	require.Equal(t, uint64(1), stats.Expired)
+	require.Equal(t, uint64(3), stats.Handled)
+
+	var drops []error
+	for _, rec := range tracer.Records() {
+		if rec.Kind == TraceDrop {
+			drops = append(drops, rec.Err)
+		}
+	}
+	require.Equal(t, []error{ErrEventExpired}, drops)
+}
+
+func TestDeadliner(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[int]{}
+	el := NewEventloop(1, 8, func(event expiring) {
+		r.handle(event.id)
+	})
+
+	past := time.Now().Add(-time.Second)
+	require.NoError(t, el.Send(expiring{id: 1, deadline: past}))
+	require.NoError(t, el.Send(expiring{id: 2}))
+	require.NoError(t, el.Send(expiring{id: 3, deadline: time.Now().Add(time.Hour)}))
+	// 명시한 deadline이 Deadliner보다 우선함
+	require.NoError(t, el.SendWithDeadline(expiring{id: 4, deadline: past}, time.Now().Add(time.Hour)))
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{2, 3, 4}, r.get())
+	require.Equal(t, uint64(1), el.Stats().Expired)
+}
+
+func TestBatchDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[[]int]{}
+	el := NewBatchEventloop(1, 8, 4, time.Hour, r.handle)
+	past := time.Now().Add(-time.Second)
+	for i := range 6 {
+		if i%2 == 0 {
+			require.NoError(t, el.SendWithDeadline(i, past))
+			continue
+		}
+		require.NoError(t, el.Send(i))
+	}
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, [][]int{{1, 3, 5}}, r.get())
+	require.Equal(t, uint64(3), el.Stats().Expired)
+}
+
+func TestCoalesceDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[int]{}
+	el := NewEventloop(1, 8, r.handle, WithCoalesce(func(int) int { return 0 }, nil))
+	require.NoError(t, el.SendWithDeadline(1, time.Now().Add(-time.Second)))
+	require.NoError(t, el.SendWithDeadline(2, time.Now().Add(time.Hour)))
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{2}, r.get())
+	require.Zero(t, el.Stats().Expired)
+}
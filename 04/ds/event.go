@@ -1,5 +1,34 @@
 package ds
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
-var ErrAlreadyClosedLoop = errors.New("already closed loop")
+var (
+	ErrAlreadyClosedLoop  = errors.New("already closed loop")
+	ErrQueueFull          = errors.New("queue is full")
+	ErrSendTimeout        = errors.New("send timeout")
+	ErrCorruptWAL         = errors.New("corrupt wal")
+	ErrEventExpired       = errors.New("event expired")
+	ErrNotHeadStage       = errors.New("stage is not the head of a pipeline")
+	ErrEventCoalesced     = errors.New("event coalesced")
+	ErrDeadLetter         = errors.New("failed to send dead letter")
+	ErrBlockingSubscriber = errors.New("subscriber cannot use OverflowBlock")
+	ErrInvalidQueueSize   = errors.New("queue size must be positive")
+)
+
+// PanicError는 handler에서 발생한 panic을 감싼 에러다.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("handler panic: %v", p.Value)
+}
+
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
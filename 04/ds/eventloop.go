@@ -1,105 +1,691 @@
 package ds
 
 import (
+	"context"
+	"fmt"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Eventloop[T any] struct {
-	queue         chan T
-	handler       func(T)
-	dispatchCount int
-	closeCh       chan struct{}
-	closed        atomic.Bool
-	forceClosed   atomic.Bool
-	state         atomic.Int32
+	queue          Queue[Envelope[T]]
+	queueSize      int
+	pushCh         chan<- Envelope[T]
+	popCh          <-chan Envelope[T]
+	notEmpty       chan struct{}
+	notFull        chan struct{}
+	handler        func(context.Context, T)
+	scaleMu        sync.Mutex
+	running        bool
+	wg             sync.WaitGroup
+	target         atomic.Int32
+	active         atomic.Int32
+	changeCh       atomic.Pointer[chan struct{}]
+	autoscale      *AutoscaleConfig
+	latencySum     atomic.Int64
+	latencyCount   atomic.Int64
+	paused         atomic.Bool
+	schedMu        sync.Mutex
+	scheduled      map[*ScheduledEvent[T]]struct{}
+	scheduledCount atomic.Int64
+	unsent         []Envelope[T]
+	schedulePolicy ScheduleClosePolicy
+	closeCh        chan struct{}
+	forceCh        chan struct{}
+	idleCh         chan struct{}
+	idleOnce       sync.Once
+	doneCh         chan struct{}
+	closed         atomic.Bool
+	forceClosed    atomic.Bool
+	state          atomic.Int32
+	overflow       OverflowPolicy
+	onOverflow     func(T)
+	onDrop         func(T, error)
+	middlewares    []Middleware[T]
+	tracer         Tracer[T]
+	onError        func(T, error)
+	retry          RetryPolicy
+	deadLetter     func(DeadLetter[T]) error
+	deadFailed     atomic.Uint64
+	batchHandler   func([]T)
+	maxBatch       int
+	maxWait        time.Duration
+	sent           atomic.Uint64
+	handled        atomic.Uint64
+	rejected       atomic.Uint64
+	dropped        atomic.Uint64
+	inFlight       atomic.Int64
+	latency        latencyHistogram
+	wal            *WAL[T]
+	spill          *spillQueue[T]
+	coalesce       coalescer[T]
+	coalesced      atomic.Uint64
+	onExpired      func(T)
+	expired        atomic.Uint64
+	replayMu       sync.Mutex
+	replay         []Envelope[T]
 }
 
-func NewEventloop[T any](dispatchCount, queueSize int, handler func(T)) *Eventloop[T] {
+// Envelope은 queue에서 이벤트와 함께 전달되는 값이다.
+// Queue를 구현할 때는 받은 값을 그대로 보관했다가 돌려주면 된다.
+type Envelope[T any] struct {
+	event T
+	ctx   context.Context
+	// seq는 WAL에 기록된 순번이며 WAL을 사용하지 않으면 0이다.
+	seq uint64
+	// slot은 WithCoalesce를 사용할 때 같은 key의 이벤트가 합쳐지는 자리다.
+	slot *coalesceSlot[T]
+	// deadline이 지난 이벤트는 handler를 호출하지 않고 버린다. zero value면 deadline이 없다.
+	deadline time.Time
+	// priority는 PriorityEventloop의 queue에서만 사용된다.
+	priority int
+}
+
+// Event는 envelope에 담긴 이벤트를 반환한다.
+// WithCoalesce로 합쳐지는 중인 이벤트는 queue에서 꺼낼 때 합쳐진 값으로 바뀐다.
+func (env Envelope[T]) Event() T {
+	return env.event
+}
+
+func NewEventloop[T any](dispatchCount, queueSize int, handler func(T), opts ...EventloopOption[T]) *Eventloop[T] {
+	e := newEventloop(dispatchCount, queueSize, opts)
+	e.setHandler(handler)
+	return e
+}
+
+func newEventloop[T any](dispatchCount, queueSize int, opts []EventloopOption[T]) *Eventloop[T] {
 	e := &Eventloop[T]{
-		queue:         make(chan T, queueSize),
-		handler:       handler,
-		dispatchCount: dispatchCount,
-		closeCh:       make(chan struct{}),
+		queue:     NewChanQueue[Envelope[T]](queueSize),
+		queueSize: queueSize,
+		scheduled: make(map[*ScheduledEvent[T]]struct{}),
+		closeCh:   make(chan struct{}),
+		forceCh:   make(chan struct{}),
+		idleCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
 	}
+	e.target.Store(int32(max(dispatchCount, 1)))
+	changeCh := make(chan struct{})
+	e.changeCh.Store(&changeCh)
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.setQueue()
+	if e.wal != nil {
+		e.replayWAL()
+	}
+	return e
+}
+
+// NewEventloopContext는 handler에 context를 함께 전달한다.
+// context는 Tracer의 OnHandlerStart가 반환한 값이며 Tracer가 없으면 context.Background()다.
+// WithMiddleware로 등록한 middleware를 거쳐도 같은 context가 handler에 전달된다.
+func NewEventloopContext[T any](dispatchCount, queueSize int, handler func(context.Context, T), opts ...EventloopOption[T]) *Eventloop[T] {
+	e := newEventloop(dispatchCount, queueSize, opts)
+	e.handler = chainContext(e.middlewares, handler)
 	return e
 }
 
+// NewEventloopWithError는 에러를 반환하는 handler를 사용한다.
+// handler의 panic은 PanicError로 복구되며 에러는 WithOnError로 등록한 콜백으로 전달된다.
+func NewEventloopWithError[T any](dispatchCount, queueSize int, handler func(T) error, opts ...EventloopOption[T]) *Eventloop[T] {
+	e := newEventloop(dispatchCount, queueSize, opts)
+	e.setHandler(func(event T) {
+		e.handleWithRetry(handler, event)
+	})
+	return e
+}
+
+// setHandler는 WithMiddleware로 등록한 middleware를 handler에 적용한다.
+func (e *Eventloop[T]) setHandler(handler func(T)) {
+	handler = chain(e.middlewares, handler)
+	if handler == nil {
+		return
+	}
+	e.handler = func(_ context.Context, event T) {
+		handler(event)
+	}
+}
+
+func (e *Eventloop[T]) handleWithRetry(handler func(T) error, event T) {
+	attempts := 0
+	for {
+		attempts++
+		err := callHandler(handler, event)
+		if err == nil {
+			return
+		}
+
+		if !e.retry.shouldRetry(err, attempts) || !e.wait(e.retry.backoff(attempts)) {
+			if e.onError != nil {
+				e.onError(event, err)
+			}
+			if e.deadLetter != nil {
+				e.sendDeadLetter(DeadLetter[T]{Event: event, Err: err, Attempts: attempts})
+			}
+			return
+		}
+	}
+}
+
+func (e *Eventloop[T]) sendDeadLetter(d DeadLetter[T]) {
+	err := e.deadLetter(d)
+	if err == nil {
+		return
+	}
+
+	e.deadFailed.Add(1)
+	if e.onError != nil {
+		e.onError(d.Event, fmt.Errorf("%w: %w", ErrDeadLetter, err))
+	}
+}
+
+// wait는 d만큼 대기하며 ForceClose되면 false를 반환한다.
+func (e *Eventloop[T]) wait(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-e.forceCh:
+		return false
+	}
+}
+
+func callHandler[T any](handler func(T) error, event T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return handler(event)
+}
+
 func (e *Eventloop[T]) Run() {
-	wg := sync.WaitGroup{}
-	for range e.dispatchCount {
-		wg.Add(1)
+	e.scaleMu.Lock()
+	e.running = true
+	for e.active.Load() < e.target.Load() {
+		e.spawn()
+	}
+	e.scaleMu.Unlock()
+
+	autoscaleWg := sync.WaitGroup{}
+	if e.autoscale != nil {
+		autoscaleWg.Add(1)
 		go func() {
-			defer wg.Done()
-			e.dispatch()
+			defer autoscaleWg.Done()
+			e.runAutoscale()
 		}()
 	}
-	wg.Wait()
+
+	e.wg.Wait()
+	autoscaleWg.Wait()
+	close(e.doneCh)
+}
+
+// spawn은 scaleMu를 잡은 상태에서 호출해야 한다.
+func (e *Eventloop[T]) spawn() {
+	e.active.Add(1)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.dispatch()
+	}()
+}
+
+// RunContext는 Run과 같지만 ctx가 끝나면 Close를 호출해 정상 종료한다.
+func (e *Eventloop[T]) RunContext(ctx context.Context) {
+	stop := context.AfterFunc(ctx, e.Close)
+	defer stop()
+	e.Run()
 }
 
 func (e *Eventloop[T]) Send(event T) error {
-	if e.closed.Load() {
-		return ErrAlreadyClosedLoop
+	return e.SendContext(context.Background(), event)
+}
+
+// SendContext는 queue가 가득 찬 동안 대기하다가 ctx가 끝나면 ctx.Err()를 반환한다.
+func (e *Eventloop[T]) SendContext(ctx context.Context, event T) error {
+	return e.send(ctx, e.wrap(ctx, event), true, nil)
+}
+
+// TrySend는 대기하지 않고 queue가 가득 찼으면 ErrQueueFull을 반환한다.
+func (e *Eventloop[T]) TrySend(event T) error {
+	return e.send(context.Background(), e.wrap(context.Background(), event), false, nil)
+}
+
+// SendTimeout은 d 동안 queue에 자리가 나지 않으면 ErrSendTimeout을 반환한다.
+func (e *Eventloop[T]) SendTimeout(event T, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	return e.send(context.Background(), e.wrap(context.Background(), event), true, timer.C)
+}
+
+// send는 wrap으로 만든 env를 queue에 넣는다.
+func (e *Eventloop[T]) send(ctx context.Context, env Envelope[T], block bool, timeout <-chan time.Time) (err error) {
+	// overflow 정책으로 버려진 이벤트는 rejected가 아닌 dropped로 집계됨
+	dropped := false
+	defer func() {
+		if env.slot != nil {
+			if err == nil {
+				e.coalesce.queued(env.slot)
+			} else {
+				e.coalesce.abort(env.slot)
+			}
+		}
+
+		if err == nil {
+			e.sent.Add(1)
+		} else if !dropped {
+			e.rejected.Add(1)
+			e.ack(env)
+			if e.tracer != nil {
+				e.tracer.OnDrop(env.ctx, env.event, err)
+			}
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
+	// closed를 확인하기 전에 state를 올려야 Close가 진행 중인 Send를 놓치지 않음
 	e.state.Add(1)
-	defer e.state.Add(-1)
+	defer e.leave()
 
-	select {
-	case <-e.closeCh:
+	if e.closed.Load() {
 		return ErrAlreadyClosedLoop
-	default:
 	}
 
+	// queue에 넣기 전에 WAL에 기록해야 Send가 성공한 이벤트가 유실되지 않음
+	if e.wal != nil {
+		if env.seq, err = e.wal.append(env.event); err != nil {
+			return err
+		}
+	}
+
+	if e.coalesce != nil {
+		if replaced, ok := e.coalesce.add(&env); ok {
+			e.coalesced.Add(1)
+			// 대기 중이던 이벤트는 합쳐진 이벤트로 바뀌었으므로 Caller처럼 이벤트마다 결과를 기다리는 쪽에 알림
+			if e.onDrop != nil {
+				e.onDrop(replaced, ErrEventCoalesced)
+			}
+			// 합쳐진 이벤트는 따로 꺼내지지 않으므로 여기서 span을 끝냄
+			if e.tracer != nil {
+				e.tracer.OnDrop(env.ctx, env.event, ErrEventCoalesced)
+			}
+			return nil
+		}
+	}
+
+	if e.spill != nil {
+		return e.sendSpill(env)
+	}
+
+	if e.push(env) {
+		return nil
+	}
+
+	if !block {
+		return ErrQueueFull
+	}
+
+	if ok, err := e.applyOverflow(env); ok {
+		dropped = err != nil
+		return err
+	}
+
+	for {
+		select {
+		case <-e.closeCh:
+			return ErrAlreadyClosedLoop
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return ErrSendTimeout
+		case e.pushCh <- env:
+			return nil
+		case <-e.notFull:
+			if e.push(env) {
+				return nil
+			}
+		}
+	}
+}
+
+// applyOverflow는 queue가 가득 찼을 때 overflow 정책을 적용한다.
+// 정책이 OverflowBlock이면 아무것도 하지 않고 false를 반환하며, 이벤트를 버렸으면 ErrQueueFull을 반환한다.
+func (e *Eventloop[T]) applyOverflow(env Envelope[T]) (bool, error) {
+	switch e.overflow {
+	case OverflowDropNewest:
+		e.drop(env, ErrQueueFull)
+		return true, ErrQueueFull
+	case OverflowDropOldest:
+		if e.sendDropOldest(env) {
+			return true, nil
+		}
+		e.drop(env, ErrQueueFull)
+		return true, ErrQueueFull
+	case OverflowCallback:
+		e.drop(env, ErrQueueFull)
+		if e.onOverflow != nil {
+			e.onOverflow(env.event)
+		}
+		return true, ErrQueueFull
+	}
+	return false, nil
+}
+
+// wrap은 tracer가 있으면 OnEnqueue가 반환한 context를 이벤트와 함께 담는다.
+// 이벤트가 Deadliner를 구현하면 deadline을, 우선순위 queue를 사용하면 우선순위도 함께 담는다.
+func (e *Eventloop[T]) wrap(ctx context.Context, event T) Envelope[T] {
+	env := Envelope[T]{event: event, deadline: deadlineOf(event)}
+	if _, ok := e.queue.(*priorityQueue[T]); ok {
+		env.priority = priorityOf(event)
+	}
+	if e.tracer != nil {
+		env.ctx = e.tracer.OnEnqueue(ctx, event)
+	}
+	return env
+}
+
+func (e *Eventloop[T]) leave() {
+	if e.state.Add(-1) == 0 && e.closed.Load() {
+		e.idle()
+	}
+}
+
+// idle은 Close 이후 진행 중인 Send가 모두 끝났을 때 호출된다.
+func (e *Eventloop[T]) idle() {
+	e.idleOnce.Do(func() {
+		close(e.idleCh)
+	})
+}
+
+// sendDropOldest는 queue에서 꺼낼 이벤트가 없어 자리를 만들 수 없으면 false를 반환한다.
+func (e *Eventloop[T]) sendDropOldest(env Envelope[T]) bool {
+	for {
+		if e.push(env) {
+			return true
+		}
+		old, ok := e.pop()
+		if !ok {
+			return false
+		}
+		e.drop(e.resolve(old), ErrQueueFull)
+	}
+}
+
+func (e *Eventloop[T]) drop(env Envelope[T], err error) {
+	e.dropped.Add(1)
+	e.ack(env)
+	if e.onDrop != nil {
+		e.onDrop(env.event, err)
+	}
+	if e.tracer != nil {
+		e.tracer.OnDrop(env.ctx, env.event, err)
+	}
+}
+
+// Pause는 dispatcher가 queue에서 이벤트를 꺼내지 않도록 멈춘다.
+// Send는 계속 가능하며 queue가 가득 차면 overflow 정책을 따른다.
+// 멈춘 상태에서 Close하면 Resume될 때까지 남은 이벤트를 처리하지 않고, ForceClose하면 남은 이벤트를 모두 반환한다.
+func (e *Eventloop[T]) Pause() {
+	if e.paused.CompareAndSwap(false, true) {
+		e.notify()
+	}
+}
+
+func (e *Eventloop[T]) Resume() {
+	if e.paused.CompareAndSwap(true, false) {
+		e.notify()
+	}
+}
+
+// Done은 Run이 끝나면, 즉 모든 dispatcher가 종료되면 닫힌다.
+func (e *Eventloop[T]) Done() <-chan struct{} {
+	return e.doneCh
+}
+
+// Shutdown은 Close 후 Send에 성공한 모든 이벤트가 처리될 때까지 대기한다.
+// 그 전에 ctx가 끝나면 ctx.Err()를 반환한다.
+func (e *Eventloop[T]) Shutdown(ctx context.Context) error {
+	e.Close()
 	select {
-	case <-e.closeCh:
-		return ErrAlreadyClosedLoop
-	case e.queue <- event:
+	case <-e.doneCh:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 func (e *Eventloop[T]) Close() {
+	// Resize가 종료 중인 loop에 dispatcher를 추가하지 않도록 scaleMu를 잡음
+	e.scaleMu.Lock()
+	defer e.scaleMu.Unlock()
+
 	if !e.closed.CompareAndSwap(false, true) {
 		return
 	}
 
 	close(e.closeCh)
+	if e.tracer != nil && !e.forceClosed.Load() {
+		e.tracer.OnClose(false)
+	}
+	e.closeScheduled()
+	if e.state.Load() == 0 {
+		e.idle()
+	}
 }
 
-func (e *Eventloop[T]) ForceClose() {
-	if !e.closed.CompareAndSwap(false, true) {
-		return
+// ForceClose는 dispatcher를 멈추고 Send에는 성공했지만 아직 꺼내지지 않은 이벤트를 반환한다.
+// 예약된 이벤트는 queue의 이벤트 뒤에 예약 시각 순서대로 포함된다.
+// WAL을 사용하면 반환된 이벤트는 처리되지 않은 것으로 남아 다음 시작 시 다시 처리된다.
+// 이미 실행 중인 handler는 끝까지 실행된다.
+func (e *Eventloop[T]) ForceClose() []T {
+	if !e.forceClosed.CompareAndSwap(false, true) {
+		return nil
 	}
 
-	close(e.closeCh)
-	for e.state.Load() != 0 {
+	close(e.forceCh)
+	if e.tracer != nil {
+		e.tracer.OnClose(true)
+	}
+	e.Close()
+	e.abortScheduled()
+	envs := e.takeReplay()
+	spilled := e.takeSpill()
+	<-e.idleCh
+
+	for {
+		env, ok := e.pop()
+		if !ok {
+			break
+		}
+		envs = append(envs, e.resolve(env))
+	}
+	envs = append(envs, spilled...)
+
+	e.schedMu.Lock()
+	envs = append(envs, e.unsent...)
+	e.unsent = nil
+	e.schedMu.Unlock()
+
+	events := make([]T, 0, len(envs))
+	for _, env := range envs {
+		if e.tracer != nil {
+			e.tracer.OnDrop(env.ctx, env.event, ErrAlreadyClosedLoop)
+		}
+		events = append(events, env.event)
 	}
-	e.forceClosed.Store(true)
+	return events
 }
 
 func (e *Eventloop[T]) dispatch() {
+	d := e.newDispatcher()
+	defer func() {
+		d.stop()
+		if !d.retired {
+			e.active.Add(-1)
+		}
+	}()
+
+	for {
+		env, ok := e.next(d)
+		if !ok {
+			return
+		}
+		env = e.dequeued(env)
+		if e.expire(env) {
+			continue
+		}
+
+		if e.batchHandler != nil {
+			e.handleBatch(e.collect(env))
+			continue
+		}
+		e.handle(env)
+	}
+}
+
+func (e *Eventloop[T]) dequeued(env Envelope[T]) Envelope[T] {
+	env = e.resolve(env)
+	if e.tracer != nil {
+		e.tracer.OnDequeue(env.ctx, env.event)
+	}
+	return env
+}
+
+func (e *Eventloop[T]) handle(env Envelope[T]) {
+	ctx := env.ctx
+	if e.tracer != nil {
+		ctx = e.tracer.OnHandlerStart(ctx, env.event)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	e.inFlight.Add(1)
+	start := time.Now()
+	e.handler(ctx, env.event)
+	e.observe(time.Since(start), 1)
+	e.ack(env)
+
+	if e.tracer != nil {
+		e.tracer.OnHandlerEnd(ctx, env.event)
+	}
+}
+
+func (e *Eventloop[T]) handleBatch(envs []Envelope[T]) {
+	batch := make([]T, len(envs))
+	ctxs := make([]context.Context, len(envs))
+	for i, env := range envs {
+		batch[i] = env.event
+		ctxs[i] = env.ctx
+		if e.tracer != nil {
+			ctxs[i] = e.tracer.OnHandlerStart(env.ctx, env.event)
+		}
+	}
+
+	e.inFlight.Add(int64(len(batch)))
+	start := time.Now()
+	e.batchHandler(batch)
+	e.observe(time.Since(start), len(batch))
+	for _, env := range envs {
+		e.ack(env)
+	}
+
+	if e.tracer != nil {
+		for i, event := range batch {
+			e.tracer.OnHandlerEnd(ctxs[i], event)
+		}
+	}
+}
+
+func (e *Eventloop[T]) observe(d time.Duration, n int) {
+	e.inFlight.Add(-int64(n))
+	e.handled.Add(uint64(n))
+	e.latency.observe(d)
+	if e.autoscale != nil {
+		e.latencySum.Add(int64(d))
+		e.latencyCount.Add(1)
+	}
+}
+
+// next는 dispatcher가 처리할 다음 이벤트를 꺼낸다.
+// Close 이후에는 queue가 빌 때까지 이벤트를 꺼내며 dispatcher가 종료해야 하면 false를 반환한다.
+func (e *Eventloop[T]) next(d *dispatcher) (Envelope[T], bool) {
+	var zero Envelope[T]
 	for {
 		select {
-		case event := <-e.queue:
-			if e.forceClosed.Load() {
-				return
+		case <-e.forceCh:
+			return zero, false
+		default:
+		}
+
+		// retire와 paused 확인 전에 changeCh를 읽어야 Resize, Resume 알림을 놓치지 않음
+		change := *e.changeCh.Load()
+		if e.retire() {
+			d.retired = true
+			return zero, false
+		}
+
+		if e.paused.Load() {
+			select {
+			case <-e.forceCh:
+				return zero, false
+			case <-change:
 			}
-			e.handler(event)
 			continue
+		}
+
+		if e.wal != nil {
+			if env, ok := e.popReplay(); ok {
+				return env, true
+			}
+		}
+
+		e.refill()
+
+		select {
+		case <-e.idleCh:
+			return e.pop()
 		default:
 		}
 
+		var env Envelope[T]
+		ok := false
 		select {
-		case event := <-e.queue:
-			if e.forceClosed.Load() {
-				return
-			}
-			e.handler(event)
-		case <-e.closeCh:
-			if e.state.Load() == 0 {
-				return
-			}
+		case <-e.forceCh:
+			return zero, false
+		case <-change:
+		case <-d.idle():
+			e.shrinkIdle()
+			d.reset()
+		case env = <-e.popCh:
+			ok = true
+		case <-e.notEmpty:
+			env, ok = e.pop()
+		case <-e.idleCh:
+		}
+
+		if ok {
+			d.reset()
+			e.refill()
+			return env, true
 		}
 	}
 }
+
+// notify는 next에서 대기 중인 dispatcher를 모두 깨운다.
+func (e *Eventloop[T]) notify() {
+	changeCh := make(chan struct{})
+	close(*e.changeCh.Swap(&changeCh))
+}
@@ -1,6 +1,8 @@
 package ds
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -20,7 +22,7 @@ func TestEventloopGoroutineLeak(t *testing.T) {
 
 	time.Sleep(time.Millisecond)
 	el.Close()
-	time.Sleep(time.Millisecond)
+	<-el.Done()
 }
 
 func TestEventloopRace(t *testing.T) {
@@ -83,7 +85,7 @@ func TestEventloopCloseSyncEnd(t *testing.T) {
 	}
 
 	el.Close()
-	time.Sleep(time.Millisecond)
+	<-el.Done()
 
 	mu.Lock()
 	defer mu.Unlock()
@@ -123,27 +125,336 @@ func TestEventloopCloseSyncRunning(t *testing.T) {
 	go el.Close()
 	wg.Done()
 	wg.Wait()
+	<-el.Done()
 
+	mu.Lock()
+	defer mu.Unlock()
 	// 정상 종료 시 Send에서 에러가 없던 이벤트는 정상 실행되어야 함
 	require.Equal(t, int(sendCount.Load()), len(processed))
 }
 
 func TestEventloopForceCloseSyncRunning(t *testing.T) {
+	block := make(chan struct{})
+	processed := atomic.Int64{}
 	handler := func(event int) {
-		time.Sleep(time.Second)
+		processed.Add(1)
+		<-block
 	}
 
-	el := NewEventloop(1024, 2, handler)
+	el := NewEventloop(2, 1024, handler)
 
 	go el.Run()
-	time.Sleep(time.Millisecond)
 
 	for i := 0; i < 10; i++ {
 		el.Send(i)
 	}
+	require.Eventually(t, func() bool {
+		return processed.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	remain := el.ForceClose()
+	close(block)
+	<-el.Done()
+
+	// 강제 종료 시 처리되지 않은 이벤트는 반환되어야 함
+	require.Len(t, remain, 8)
+	require.Equal(t, int64(2), processed.Load())
+	require.Equal(t, 0, el.queue.Len())
+	require.ErrorIs(t, el.Send(10), ErrAlreadyClosedLoop)
+}
+
+func TestEventloopForceCloseAfterClose(t *testing.T) {
+	handler := func(event int) {}
+	el := NewEventloop(1, 1024, handler)
+
+	for i := range 5 {
+		require.NoError(t, el.Send(i))
+	}
+	el.Close()
+
+	// Run 전이므로 queue의 이벤트가 모두 반환되어야 함
+	require.Equal(t, []int{0, 1, 2, 3, 4}, el.ForceClose())
+	require.Nil(t, el.ForceClose())
+}
+
+func TestEventloopSendContextCancel(t *testing.T) {
+	handler := func(event int) {}
+	el := NewEventloop(1, 1, handler)
+
+	// Run 없이 queue를 가득 채움
+	err := el.Send(0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = el.SendContext(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = el.SendContext(ctx, 2)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestEventloopRunContext(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	processed := atomic.Int64{}
+	handler := func(event int) {
+		processed.Add(1)
+	}
+	el := NewEventloop(2, 1024, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	end := make(chan struct{})
+	go func() {
+		el.RunContext(ctx)
+		close(end)
+	}()
+
+	for i := range 10 {
+		err := el.Send(i)
+		require.NoError(t, err)
+	}
+
+	cancel()
+	<-end
+
+	require.Equal(t, int64(10), processed.Load())
+	require.ErrorIs(t, el.Send(10), ErrAlreadyClosedLoop)
+}
+
+func TestEventloopTrySend(t *testing.T) {
+	handler := func(event int) {}
+	el := NewEventloop(1, 2, handler)
+
+	require.NoError(t, el.TrySend(0))
+	require.NoError(t, el.TrySend(1))
+	require.ErrorIs(t, el.TrySend(2), ErrQueueFull)
+
+	el.Close()
+	require.ErrorIs(t, el.TrySend(3), ErrAlreadyClosedLoop)
+}
+
+func TestEventloopSendTimeout(t *testing.T) {
+	handler := func(event int) {}
+	el := NewEventloop(1, 1, handler)
+
+	require.NoError(t, el.SendTimeout(0, time.Millisecond))
+
+	start := time.Now()
+	err := el.SendTimeout(1, 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrSendTimeout)
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	go el.Run()
+	require.NoError(t, el.SendTimeout(2, time.Second))
+	el.Close()
+}
+
+func TestEventloopOverflowDropNewest(t *testing.T) {
+	var mu sync.Mutex
+	var processed []int
+	handler := func(event int) {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, event)
+	}
+	el := NewEventloop(1, 2, handler, WithOverflowPolicy[int](OverflowDropNewest))
+
+	require.NoError(t, el.Send(0))
+	require.NoError(t, el.Send(1))
+	require.ErrorIs(t, el.Send(2), ErrQueueFull)
+	require.Equal(t, uint64(1), el.Stats().Dropped)
+
+	go el.Run()
+	el.Close()
+	<-el.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{0, 1}, processed)
+}
+
+func TestEventloopOverflowDropOldest(t *testing.T) {
+	var mu sync.Mutex
+	var processed []int
+	handler := func(event int) {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, event)
+	}
+	el := NewEventloop(1, 2, handler, WithOverflowPolicy[int](OverflowDropOldest))
 
-	el.ForceClose()
+	for i := range 5 {
+		require.NoError(t, el.Send(i))
+	}
+	stats := el.Stats()
+	require.Equal(t, uint64(3), stats.Dropped)
+	require.Equal(t, 2, stats.QueueLen)
+	require.Equal(t, 2, stats.QueueCap)
+
+	go el.Run()
+	el.Close()
+	<-el.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{3, 4}, processed)
+}
+
+func TestEventloopOverflowDropOldestUnbuffered(t *testing.T) {
+	el := NewEventloop(1, 0, func(event int) {}, WithOverflowPolicy[int](OverflowDropOldest))
+
+	// 버릴 이벤트가 없으면 새 이벤트를 버려야 함
+	require.ErrorIs(t, el.Send(1), ErrQueueFull)
+	require.Equal(t, uint64(1), el.Stats().Dropped)
+	require.Empty(t, el.ForceClose())
+}
+
+func TestEventloopOverflowCallback(t *testing.T) {
+	var rejected []int
+	handler := func(event int) {}
+	el := NewEventloop(1, 1, handler, WithOverflowCallback(func(event int) {
+		rejected = append(rejected, event)
+	}))
+
+	require.NoError(t, el.Send(0))
+	require.ErrorIs(t, el.Send(1), ErrQueueFull)
+	require.ErrorIs(t, el.Send(2), ErrQueueFull)
+	require.Equal(t, []int{1, 2}, rejected)
+	require.Equal(t, uint64(2), el.Stats().Dropped)
+}
+
+func TestEventloopShutdown(t *testing.T) {
+	processed := atomic.Int64{}
+	handler := func(event int) {
+		time.Sleep(time.Millisecond)
+		processed.Add(1)
+	}
+	el := NewEventloop(2, 1024, handler)
+
+	go el.Run()
+
+	for i := range 20 {
+		require.NoError(t, el.Send(i))
+	}
+
+	err := el.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(20), processed.Load())
+
+	select {
+	case <-el.Done():
+	default:
+		require.Fail(t, "done channel is not closed")
+	}
+}
+
+func TestEventloopShutdownTimeout(t *testing.T) {
+	block := make(chan struct{})
+	handler := func(event int) {
+		<-block
+	}
+	el := NewEventloop(1, 1024, handler)
+
+	go el.Run()
+	require.NoError(t, el.Send(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := el.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(block)
+	<-el.Done()
+}
+
+func TestEventloopWithErrorRecover(t *testing.T) {
+	errOdd := errors.New("odd event")
+
+	var mu sync.Mutex
+	failed := make(map[int]error)
+	processed := atomic.Int64{}
+	handler := func(event int) error {
+		if event == 0 {
+			panic("poisoned event")
+		}
+		if event%2 == 1 {
+			return errOdd
+		}
+		processed.Add(1)
+		return nil
+	}
+	onError := func(event int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed[event] = err
+	}
+	el := NewEventloopWithError(2, 1024, handler, WithOnError(onError))
+
+	go el.Run()
+
+	for i := range 10 {
+		require.NoError(t, el.Send(i))
+	}
+	el.Close()
+	<-el.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, failed, 6)
+	require.Equal(t, int64(4), processed.Load())
+
+	var panicErr *PanicError
+	require.ErrorAs(t, failed[0], &panicErr)
+	require.Equal(t, "poisoned event", panicErr.Value)
+	require.NotEmpty(t, panicErr.Stack)
+	require.ErrorIs(t, failed[1], errOdd)
+}
+
+func TestEventloopPauseResume(t *testing.T) {
+	processed := atomic.Int64{}
+	handler := func(event int) {
+		processed.Add(1)
+	}
+	el := NewEventloop(2, 1024, handler)
+
+	go el.Run()
+	el.Pause()
+	require.True(t, el.Stats().Paused)
+
+	for i := range 10 {
+		require.NoError(t, el.Send(i))
+	}
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int64(0), processed.Load())
+	require.Equal(t, 10, el.Stats().QueueLen)
+
+	// 멈춘 상태에서는 Close해도 이벤트를 처리하지 않음
+	el.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, el.Shutdown(ctx), context.DeadlineExceeded)
+
+	el.Resume()
+	require.False(t, el.Stats().Paused)
+	<-el.Done()
+	require.Equal(t, int64(10), processed.Load())
+}
+
+func TestEventloopPauseForceClose(t *testing.T) {
+	handler := func(event int) {}
+	el := NewEventloop(2, 1024, handler)
+
+	go el.Run()
+	el.Pause()
+
+	for i := range 5 {
+		require.NoError(t, el.Send(i))
+	}
 
-	// 강제 종료 시 queue에 원소가 남아있는 상태
-	require.Less(t, 0, len(el.queue))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, el.ForceClose())
+	<-el.Done()
 }
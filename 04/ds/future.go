@@ -0,0 +1,133 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Future는 Caller에 보낸 이벤트의 처리 결과를 전달받는다.
+type Future[R any] struct {
+	once   sync.Once
+	done   chan struct{}
+	result R
+	err    error
+}
+
+func newFuture[R any]() *Future[R] {
+	return &Future[R]{done: make(chan struct{})}
+}
+
+func (f *Future[R]) resolve(result R, err error) {
+	f.once.Do(func() {
+		f.result = result
+		f.err = err
+		close(f.done)
+	})
+}
+
+func (f *Future[R]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await는 결과가 나올 때까지 대기한다. 그 전에 ctx가 끝나면 ctx.Err()를 반환한다.
+func (f *Future[R]) Await(ctx context.Context) (R, error) {
+	select {
+	case <-f.done:
+		return f.result, f.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+// Call은 Caller의 queue에 들어가는 요청이다.
+type Call[T, R any] struct {
+	Event  T
+	future *Future[R]
+}
+
+// Caller는 handler의 결과를 Future로 돌려주는 Eventloop이다.
+// handler의 에러와 panic, Send 실패, 버려진 요청은 모두 Future의 에러로 전달된다.
+type Caller[T, R any] struct {
+	loop *Eventloop[Call[T, R]]
+}
+
+func NewCaller[T, R any](dispatchCount, queueSize int, handler func(T) (R, error), opts ...EventloopOption[Call[T, R]]) *Caller[T, R] {
+	loop := NewEventloop(dispatchCount, queueSize, func(c Call[T, R]) {
+		var result R
+		err := callHandler(func(event T) (err error) {
+			result, err = handler(event)
+			return err
+		}, c.Event)
+		c.future.resolve(result, err)
+	}, opts...)
+	loop.onDrop = func(c Call[T, R], err error) {
+		var zero R
+		c.future.resolve(zero, err)
+	}
+	return &Caller[T, R]{loop: loop}
+}
+
+func (c *Caller[T, R]) Loop() *Eventloop[Call[T, R]] {
+	return c.loop
+}
+
+func (c *Caller[T, R]) Run() {
+	c.loop.Run()
+}
+
+func (c *Caller[T, R]) RunContext(ctx context.Context) {
+	c.loop.RunContext(ctx)
+}
+
+func (c *Caller[T, R]) Send(event T) *Future[R] {
+	return c.SendContext(context.Background(), event)
+}
+
+func (c *Caller[T, R]) SendContext(ctx context.Context, event T) *Future[R] {
+	f := newFuture[R]()
+	if err := c.loop.SendContext(ctx, Call[T, R]{Event: event, future: f}); err != nil {
+		var zero R
+		f.resolve(zero, err)
+	}
+	return f
+}
+
+func (c *Caller[T, R]) SendTimeout(event T, d time.Duration) *Future[R] {
+	f := newFuture[R]()
+	if err := c.loop.SendTimeout(Call[T, R]{Event: event, future: f}, d); err != nil {
+		var zero R
+		f.resolve(zero, err)
+	}
+	return f
+}
+
+// Do는 이벤트를 보내고 결과를 기다린다.
+func (c *Caller[T, R]) Do(ctx context.Context, event T) (R, error) {
+	return c.SendContext(ctx, event).Await(ctx)
+}
+
+func (c *Caller[T, R]) Done() <-chan struct{} {
+	return c.loop.Done()
+}
+
+func (c *Caller[T, R]) Close() {
+	c.loop.Close()
+}
+
+func (c *Caller[T, R]) Shutdown(ctx context.Context) error {
+	return c.loop.Shutdown(ctx)
+}
+
+// ForceClose는 처리되지 않은 요청의 Future를 ErrAlreadyClosedLoop로 완료하고 그 이벤트를 반환한다.
+func (c *Caller[T, R]) ForceClose() []T {
+	calls := c.loop.ForceClose()
+	events := make([]T, 0, len(calls))
+	for _, call := range calls {
+		var zero R
+		call.future.resolve(zero, ErrAlreadyClosedLoop)
+		events = append(events, call.Event)
+	}
+	return events
+}
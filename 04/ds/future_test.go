@@ -0,0 +1,106 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaller(t *testing.T) {
+	errNegative := errors.New("negative")
+	handler := func(event int) (string, error) {
+		if event < 0 {
+			return "", errNegative
+		}
+		if event == 0 {
+			panic("zero")
+		}
+		return strconv.Itoa(event), nil
+	}
+	c := NewCaller(2, 16, handler)
+	go c.Run()
+
+	ctx := context.Background()
+	r, err := c.Do(ctx, 42)
+	require.NoError(t, err)
+	require.Equal(t, "42", r)
+
+	_, err = c.Send(-1).Await(ctx)
+	require.ErrorIs(t, err, errNegative)
+
+	var panicErr *PanicError
+	_, err = c.Send(0).Await(ctx)
+	require.ErrorAs(t, err, &panicErr)
+
+	require.NoError(t, c.Shutdown(ctx))
+	_, err = c.Send(1).Await(ctx)
+	require.ErrorIs(t, err, ErrAlreadyClosedLoop)
+}
+
+func TestCallerAwaitContext(t *testing.T) {
+	block := make(chan struct{})
+	handler := func(event int) (int, error) {
+		<-block
+		return event, nil
+	}
+	c := NewCaller(1, 16, handler)
+	go c.Run()
+
+	f := c.Send(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := f.Await(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(block)
+	r, err := f.Await(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, r)
+	c.Close()
+}
+
+func TestCallerDropAndForceClose(t *testing.T) {
+	handler := func(event int) (int, error) {
+		return event, nil
+	}
+	c := NewCaller(1, 1, handler, WithOverflowPolicy[Call[int, int]](OverflowDropOldest))
+
+	ctx := context.Background()
+	dropped := c.Send(1)
+	pending := c.Send(2)
+
+	_, err := dropped.Await(ctx)
+	require.ErrorIs(t, err, ErrQueueFull)
+
+	require.Equal(t, []int{2}, c.ForceClose())
+	_, err = pending.Await(ctx)
+	require.ErrorIs(t, err, ErrAlreadyClosedLoop)
+}
+
+func TestCallerCoalesce(t *testing.T) {
+	handler := func(event update) (int, error) {
+		return event.Value, nil
+	}
+	c := NewCaller(1, 4, handler, WithCoalesce(func(c Call[update, int]) string {
+		return c.Event.Key
+	}, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	f1 := c.Send(update{Key: "a", Value: 1})
+	f2 := c.Send(update{Key: "a", Value: 2})
+
+	// 대기 중이던 요청은 합쳐졌다는 에러로 끝나야 함
+	_, err := f1.Await(ctx)
+	require.ErrorIs(t, err, ErrEventCoalesced)
+
+	go c.Run()
+	result, err := f2.Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, result)
+	require.NoError(t, c.Shutdown(ctx))
+}
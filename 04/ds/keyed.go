@@ -0,0 +1,186 @@
+package ds
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+)
+
+// KeyedEventloop은 이벤트의 key를 해시하여 dispatcher가 하나인 shard에 배정한다.
+// 같은 key의 이벤트는 항상 같은 shard로 가므로 key 단위로 FIFO 순서가 보장된다.
+type KeyedEventloop[K comparable, T any] struct {
+	shards     []*Eventloop[T]
+	key        func(T) K
+	hash       func(K) uint64
+	queueSizes []int
+	shardOpts  []EventloopOption[T]
+	doneCh     chan struct{}
+}
+
+type KeyedOption[K comparable, T any] func(*KeyedEventloop[K, T])
+
+func WithKeyHash[K comparable, T any](hash func(K) uint64) KeyedOption[K, T] {
+	return func(k *KeyedEventloop[K, T]) {
+		k.hash = hash
+	}
+}
+
+// WithShardQueueSizes는 shard별 queue 크기를 지정한다. 지정하지 않은 shard는 기본 queueSize를 사용한다.
+func WithShardQueueSizes[K comparable, T any](sizes ...int) KeyedOption[K, T] {
+	return func(k *KeyedEventloop[K, T]) {
+		k.queueSizes = sizes
+	}
+}
+
+// WithShardOptions는 모든 shard의 Eventloop에 적용할 옵션을 지정한다.
+// key 단위의 순서를 지키기 위해 shard의 dispatcher는 항상 하나이며 WithAutoscale은 무시된다.
+func WithShardOptions[K comparable, T any](opts ...EventloopOption[T]) KeyedOption[K, T] {
+	return func(k *KeyedEventloop[K, T]) {
+		k.shardOpts = append(k.shardOpts, opts...)
+	}
+}
+
+// NewKeyedEventloop은 shardCount가 1보다 작으면 1로 맞춘다.
+func NewKeyedEventloop[K comparable, T any](shardCount, queueSize int, key func(T) K, handler func(T), opts ...KeyedOption[K, T]) *KeyedEventloop[K, T] {
+	k := &KeyedEventloop[K, T]{
+		shards: make([]*Eventloop[T], max(shardCount, 1)),
+		key:    key,
+		hash:   defaultKeyHash[K],
+		doneCh: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	for i := range k.shards {
+		size := queueSize
+		if i < len(k.queueSizes) {
+			size = k.queueSizes[i]
+		}
+		k.shards[i] = NewEventloop(1, size, handler, append(slices.Clip(k.shardOpts), singleDispatcher[T])...)
+	}
+	return k
+}
+
+func (k *KeyedEventloop[K, T]) Run() {
+	wg := sync.WaitGroup{}
+	for _, shard := range k.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shard.Run()
+		}()
+	}
+	wg.Wait()
+	close(k.doneCh)
+}
+
+func (k *KeyedEventloop[K, T]) RunContext(ctx context.Context) {
+	stop := context.AfterFunc(ctx, k.Close)
+	defer stop()
+	k.Run()
+}
+
+// singleDispatcher는 shard의 옵션 중 마지막에 적용되어 dispatcher 수를 하나로 고정한다.
+func singleDispatcher[T any](e *Eventloop[T]) {
+	e.autoscale = nil
+	e.target.Store(1)
+}
+
+// Shard는 이벤트가 배정되는 shard의 번호를 반환한다.
+// shard의 Eventloop을 직접 노출하면 Resize로 dispatcher가 늘어 순서가 깨질 수 있으므로 번호만 반환한다.
+func (k *KeyedEventloop[K, T]) Shard(event T) int {
+	return int(k.hash(k.key(event)) % uint64(len(k.shards)))
+}
+
+// ShardStats는 i번째 shard의 Stats를 반환한다.
+func (k *KeyedEventloop[K, T]) ShardStats(i int) EventloopStats {
+	return k.shards[i].Stats()
+}
+
+func (k *KeyedEventloop[K, T]) shard(event T) *Eventloop[T] {
+	return k.shards[k.Shard(event)]
+}
+
+func (k *KeyedEventloop[K, T]) Send(event T) error {
+	return k.shard(event).Send(event)
+}
+
+func (k *KeyedEventloop[K, T]) SendContext(ctx context.Context, event T) error {
+	return k.shard(event).SendContext(ctx, event)
+}
+
+func (k *KeyedEventloop[K, T]) TrySend(event T) error {
+	return k.shard(event).TrySend(event)
+}
+
+func (k *KeyedEventloop[K, T]) SendTimeout(event T, d time.Duration) error {
+	return k.shard(event).SendTimeout(event, d)
+}
+
+func (k *KeyedEventloop[K, T]) Done() <-chan struct{} {
+	return k.doneCh
+}
+
+func (k *KeyedEventloop[K, T]) Shutdown(ctx context.Context) error {
+	k.Close()
+	select {
+	case <-k.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (k *KeyedEventloop[K, T]) Close() {
+	for _, shard := range k.shards {
+		shard.Close()
+	}
+}
+
+// ForceClose는 모든 shard를 강제 종료하고 처리되지 않은 이벤트를 shard 순서대로 반환한다.
+func (k *KeyedEventloop[K, T]) ForceClose() []T {
+	var events []T
+	for _, shard := range k.shards {
+		events = append(events, shard.ForceClose()...)
+	}
+	return events
+}
+
+func defaultKeyHash[K comparable](key K) uint64 {
+	switch v := any(key).(type) {
+	case int:
+		return mix64(uint64(v))
+	case int32:
+		return mix64(uint64(v))
+	case int64:
+		return mix64(uint64(v))
+	case uint:
+		return mix64(uint64(v))
+	case uint32:
+		return mix64(uint64(v))
+	case uint64:
+		return mix64(v)
+	case string:
+		h := fnv.New64a()
+		h.Write([]byte(v))
+		return h.Sum64()
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v", key)
+	return h.Sum64()
+}
+
+// mix64는 splitmix64의 finalizer다.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
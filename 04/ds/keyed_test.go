@@ -0,0 +1,117 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type keyedEvent struct {
+	user string
+	seq  int
+}
+
+func TestKeyedEventloopOrder(t *testing.T) {
+	var mu sync.Mutex
+	seqs := make(map[string][]int)
+	handler := func(event keyedEvent) {
+		time.Sleep(time.Microsecond)
+		mu.Lock()
+		defer mu.Unlock()
+		seqs[event.user] = append(seqs[event.user], event.seq)
+	}
+	key := func(event keyedEvent) string {
+		return event.user
+	}
+	el := NewKeyedEventloop(4, 16, key, handler)
+
+	go el.Run()
+
+	users := []string{"a", "b", "c", "d", "e", "f"}
+	n := 50
+	for i := range n {
+		for _, user := range users {
+			require.NoError(t, el.Send(keyedEvent{user: user, seq: i}))
+		}
+	}
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, user := range users {
+		require.Len(t, seqs[user], n)
+		for i, seq := range seqs[user] {
+			require.Equal(t, i, seq)
+		}
+	}
+}
+
+func TestKeyedEventloopOptions(t *testing.T) {
+	handler := func(event int) {}
+	key := func(event int) int {
+		return event
+	}
+	el := NewKeyedEventloop(2, 8, key, handler,
+		WithKeyHash[int, int](func(k int) uint64 {
+			return uint64(k)
+		}),
+		WithShardQueueSizes[int, int](1, 3),
+		WithShardOptions[int](WithOverflowPolicy[int](OverflowDropNewest)),
+	)
+
+	require.Equal(t, 1, el.ShardStats(0).QueueCap)
+	require.Equal(t, 3, el.ShardStats(1).QueueCap)
+	require.Equal(t, 1, el.Shard(3))
+
+	require.NoError(t, el.Send(0))
+	require.ErrorIs(t, el.Send(2), ErrQueueFull)
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(3))
+
+	require.Equal(t, []int{0, 1, 3}, el.ForceClose())
+}
+
+func TestKeyedEventloopZeroShards(t *testing.T) {
+	var mu sync.Mutex
+	var processed []int
+	handler := func(event int) {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, event)
+	}
+	key := func(event int) int {
+		return event
+	}
+	el := NewKeyedEventloop(0, 4, key, handler)
+
+	go el.Run()
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(2))
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{1, 2}, processed)
+}
+
+func TestKeyedEventloopSingleDispatcher(t *testing.T) {
+	key := func(event int) int {
+		return event
+	}
+	el := NewKeyedEventloop(2, 8, key, func(event int) {},
+		WithShardOptions[int](WithAutoscale[int](AutoscaleConfig{Min: 4, Max: 8})),
+	)
+	go el.Run()
+
+	// autoscale은 무시되고 shard마다 dispatcher는 하나여야 함
+	for i := range 2 {
+		require.Eventually(t, func() bool {
+			return el.ShardStats(i).Dispatchers == 1
+		}, time.Second, time.Millisecond)
+		require.Nil(t, el.shards[i].autoscale)
+	}
+	require.NoError(t, el.Shutdown(context.Background()))
+}
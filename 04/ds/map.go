@@ -59,6 +59,12 @@ func (m *Map[F, T]) LoadOrStore(k F, v T) (T, bool) {
 	return v, true
 }
 
+func (m *Map[F, T]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.m)
+}
+
 func (m *Map[F, T]) Range(f func(F, T) bool) {
 	m.mu.RLock()
 	length := len(m.m)
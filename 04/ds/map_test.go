@@ -47,6 +47,8 @@ func TestMapWork(t *testing.T) {
 	require.Equal(t, 1000, p)
 	require.Equal(t, false, loaded)
 
+	require.Equal(t, 2, m.Len())
+
 	keys := make(map[int]int)
 	m.Range(func(k int, v int) bool {
 		keys[k] = v
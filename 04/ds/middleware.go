@@ -0,0 +1,83 @@
+package ds
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// Middleware는 handler를 감싸는 함수다.
+type Middleware[T any] func(next func(T)) func(T)
+
+// WithMiddleware로 등록한 middleware는 등록한 순서대로 바깥쪽부터 handler를 감싼다.
+func WithMiddleware[T any](middlewares ...Middleware[T]) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.middlewares = append(e.middlewares, middlewares...)
+	}
+}
+
+func chain[T any](middlewares []Middleware[T], handler func(T)) func(T) {
+	if handler == nil {
+		return nil
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// chainContext는 chain과 같지만 handler가 받은 context를 middleware 너머의 handler로 전달한다.
+func chainContext[T any](middlewares []Middleware[T], handler func(context.Context, T)) func(context.Context, T) {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], handler
+		handler = func(ctx context.Context, event T) {
+			middleware(func(event T) {
+				next(ctx, event)
+			})(event)
+		}
+	}
+	return handler
+}
+
+// RecoverMiddleware는 handler의 panic을 복구하여 onPanic으로 전달한다.
+func RecoverMiddleware[T any](onPanic func(T, *PanicError)) Middleware[T] {
+	return func(next func(T)) func(T) {
+		return func(event T) {
+			defer func() {
+				if r := recover(); r != nil && onPanic != nil {
+					onPanic(event, &PanicError{Value: r, Stack: debug.Stack()})
+				}
+			}()
+			next(event)
+		}
+	}
+}
+
+// LatencyMiddleware는 handler의 실행 시간을 observe로 전달한다.
+func LatencyMiddleware[T any](observe func(T, time.Duration)) Middleware[T] {
+	return func(next func(T)) func(T) {
+		return func(event T) {
+			start := time.Now()
+			defer func() {
+				observe(event, time.Since(start))
+			}()
+			next(event)
+		}
+	}
+}
+
+// LoggingMiddleware는 handler의 실행이 끝나면 이벤트와 실행 시간을 logger에 기록한다.
+func LoggingMiddleware[T any](logger *slog.Logger, level slog.Level) Middleware[T] {
+	return func(next func(T)) func(T) {
+		return func(event T) {
+			start := time.Now()
+			next(event)
+			logger.Log(context.Background(), level, "event handled",
+				slog.Any("event", event),
+				slog.Duration("duration", time.Since(start)),
+			)
+		}
+	}
+}
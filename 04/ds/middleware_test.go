@@ -0,0 +1,86 @@
+package ds
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) Middleware[int] {
+		return func(next func(int)) func(int) {
+			return func(event int) {
+				mu.Lock()
+				calls = append(calls, name+">")
+				mu.Unlock()
+				next(event)
+				mu.Lock()
+				calls = append(calls, "<"+name)
+				mu.Unlock()
+			}
+		}
+	}
+	handler := func(event int) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, "handler")
+	}
+	el := NewEventloop(1, 16, handler, WithMiddleware(record("a"), record("b")), WithMiddleware(record("c")))
+
+	go el.Run()
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"a>", "b>", "c>", "handler", "<c", "<b", "<a"}, calls)
+}
+
+func TestBuiltinMiddlewares(t *testing.T) {
+	var mu sync.Mutex
+	panics := make(map[int]*PanicError)
+	latencies := make(map[int]time.Duration)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	handler := func(event int) {
+		if event == 0 {
+			panic("boom")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	el := NewEventloop(1, 16, handler, WithMiddleware(
+		LoggingMiddleware[int](logger, slog.LevelInfo),
+		RecoverMiddleware(func(event int, err *PanicError) {
+			mu.Lock()
+			defer mu.Unlock()
+			panics[event] = err
+		}),
+		LatencyMiddleware(func(event int, d time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			latencies[event] = d
+		}),
+	))
+
+	go el.Run()
+	require.NoError(t, el.Send(0))
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, panics, 1)
+	require.Equal(t, "boom", panics[0].Value)
+	require.Len(t, latencies, 2)
+	require.GreaterOrEqual(t, latencies[1], time.Millisecond)
+	require.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("msg=\"event handled\"")))
+	require.Contains(t, buf.String(), "event=1")
+}
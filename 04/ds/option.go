@@ -0,0 +1,50 @@
+package ds
+
+type EventloopOption[T any] func(*Eventloop[T])
+
+type OverflowPolicy int
+
+const (
+	// OverflowBlock는 queue에 자리가 날 때까지 Send를 대기시킨다.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest는 새로 들어온 이벤트를 버리고 ErrQueueFull을 반환한다.
+	OverflowDropNewest
+	// OverflowDropOldest는 queue에서 가장 오래된 이벤트를 버리고 새 이벤트를 넣는다.
+	// queue 크기가 0이라 버릴 이벤트가 없으면 새 이벤트를 버리고 ErrQueueFull을 반환한다.
+	OverflowDropOldest
+	// OverflowCallback은 새로 들어온 이벤트를 콜백으로 넘기고 ErrQueueFull을 반환한다.
+	OverflowCallback
+)
+
+func WithOverflowPolicy[T any](policy OverflowPolicy) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.overflow = policy
+	}
+}
+
+func WithOverflowCallback[T any](f func(T)) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.overflow = OverflowCallback
+		e.onOverflow = f
+	}
+}
+
+func WithOnError[T any](f func(T, error)) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.onError = f
+	}
+}
+
+func WithRetry[T any](policy RetryPolicy) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.retry = policy
+	}
+}
+
+// WithDeadLetter는 재시도 후에도 실패한 이벤트를 sink로 전달한다.
+// sink가 에러를 반환하면 Stats의 DeadLetterFailed가 증가하고 ErrDeadLetter로 감싼 에러가 WithOnError의 콜백으로 전달된다.
+func WithDeadLetter[T any](sink func(DeadLetter[T]) error) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.deadLetter = sink
+	}
+}
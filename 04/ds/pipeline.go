@@ -0,0 +1,207 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Stage는 In 이벤트를 Out으로 변환하여 하위 stage로 넘기는 pipeline의 한 단계다.
+// 각 stage는 자신의 Eventloop을 가지며 하위 stage의 queue가 가득 차면 Send에서 대기하므로
+// backpressure가 상위 stage를 거쳐 Pipeline.Send까지 전파된다.
+// 하위 stage의 overflow 정책 등으로 출력을 보내지 못하면 WithOnError로 등록한 콜백에 입력 이벤트와 에러가 전달된다.
+type Stage[In, Out any] struct {
+	chain *stageChain
+	up    stage
+	loop  *Eventloop[In]
+	emits []func(Out) error
+}
+
+type stage interface {
+	run()
+	close()
+	forceClose()
+	done() <-chan struct{}
+	upstream() stage
+}
+
+type stageChain struct {
+	mu     sync.Mutex
+	stages []stage
+}
+
+// Pipeline은 Stage들을 묶어 함께 실행하고 종료한다.
+// Close하면 상위 stage부터 차례로 남은 이벤트를 모두 처리한 뒤 하위 stage를 닫는다.
+type Pipeline[T any] struct {
+	head   *Eventloop[T]
+	chain  *stageChain
+	doneCh chan struct{}
+}
+
+// NewStage는 pipeline의 첫 stage를 만든다. fn이 false를 반환하면 이벤트는 하위 stage로 전달되지 않는다.
+func NewStage[In, Out any](dispatchCount, queueSize int, fn func(In) (Out, bool), opts ...EventloopOption[In]) *Stage[In, Out] {
+	return newStage(&stageChain{}, nil, dispatchCount, queueSize, fn, opts...)
+}
+
+// Then은 s의 출력을 입력으로 받는 stage를 연결한다. 한 stage에 여러 stage를 연결하면 출력이 모두에게 전달된다.
+// 모든 stage는 Pipeline.Run 전에 연결해야 한다.
+func Then[In, Mid, Out any](s *Stage[In, Mid], dispatchCount, queueSize int, fn func(Mid) (Out, bool), opts ...EventloopOption[Mid]) *Stage[Mid, Out] {
+	next := newStage(s.chain, s, dispatchCount, queueSize, fn, opts...)
+	s.emits = append(s.emits, next.loop.Send)
+	return next
+}
+
+// Sink는 s의 출력을 handler로 처리하는 마지막 stage를 연결한다.
+func Sink[In, Out any](s *Stage[In, Out], dispatchCount, queueSize int, handler func(Out), opts ...EventloopOption[Out]) *Stage[Out, struct{}] {
+	return Then(s, dispatchCount, queueSize, func(event Out) (struct{}, bool) {
+		handler(event)
+		return struct{}{}, false
+	}, opts...)
+}
+
+func newStage[In, Out any](chain *stageChain, up stage, dispatchCount, queueSize int, fn func(In) (Out, bool), opts ...EventloopOption[In]) *Stage[In, Out] {
+	s := &Stage[In, Out]{
+		chain: chain,
+		up:    up,
+	}
+	s.loop = NewEventloop(dispatchCount, queueSize, func(event In) {
+		out, ok := fn(event)
+		if !ok {
+			return
+		}
+		for _, emit := range s.emits {
+			// 하위 stage는 상위 stage가 끝난 뒤에 닫히므로 Close 중에는 실패하지 않으며
+			// overflow 정책이나 ForceClose로 실패한 이벤트는 하위 stage의 Stats에도 집계된다.
+			if err := emit(out); err != nil && s.loop.onError != nil {
+				s.loop.onError(event, err)
+			}
+		}
+	}, opts...)
+
+	chain.mu.Lock()
+	chain.stages = append(chain.stages, s)
+	chain.mu.Unlock()
+	return s
+}
+
+func (s *Stage[In, Out]) Stats() EventloopStats {
+	return s.loop.Stats()
+}
+
+func (s *Stage[In, Out]) run() {
+	s.loop.Run()
+}
+
+func (s *Stage[In, Out]) close() {
+	s.loop.Close()
+}
+
+// forceClose는 처리되지 않은 이벤트를 버리고 Stats의 Dropped로 집계한다.
+func (s *Stage[In, Out]) forceClose() {
+	s.loop.dropped.Add(uint64(len(s.loop.ForceClose())))
+}
+
+func (s *Stage[In, Out]) done() <-chan struct{} {
+	return s.loop.Done()
+}
+
+func (s *Stage[In, Out]) upstream() stage {
+	return s.up
+}
+
+// NewPipeline은 NewStage로 만든 첫 stage부터 연결된 stage들을 묶는다.
+// Then이나 Sink로 만든 중간 stage를 넘기면 ErrNotHeadStage를 반환한다.
+func NewPipeline[T, Out any](head *Stage[T, Out]) (*Pipeline[T], error) {
+	if head.up != nil {
+		return nil, ErrNotHeadStage
+	}
+	return &Pipeline[T]{
+		head:   head.loop,
+		chain:  head.chain,
+		doneCh: make(chan struct{}),
+	}, nil
+}
+
+func (p *Pipeline[T]) stages() []stage {
+	p.chain.mu.Lock()
+	defer p.chain.mu.Unlock()
+	return append([]stage(nil), p.chain.stages...)
+}
+
+// Run은 모든 stage를 실행하고 마지막 stage까지 종료되면 반환한다.
+func (p *Pipeline[T]) Run() {
+	wg := sync.WaitGroup{}
+	for _, st := range p.stages() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st.run()
+		}()
+
+		up := st.upstream()
+		if up == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-up.done()
+			st.close()
+		}()
+	}
+	wg.Wait()
+	close(p.doneCh)
+}
+
+func (p *Pipeline[T]) RunContext(ctx context.Context) {
+	stop := context.AfterFunc(ctx, p.Close)
+	defer stop()
+	p.Run()
+}
+
+func (p *Pipeline[T]) Send(event T) error {
+	return p.head.Send(event)
+}
+
+func (p *Pipeline[T]) SendContext(ctx context.Context, event T) error {
+	return p.head.SendContext(ctx, event)
+}
+
+func (p *Pipeline[T]) TrySend(event T) error {
+	return p.head.TrySend(event)
+}
+
+func (p *Pipeline[T]) SendTimeout(event T, d time.Duration) error {
+	return p.head.SendTimeout(event, d)
+}
+
+func (p *Pipeline[T]) Done() <-chan struct{} {
+	return p.doneCh
+}
+
+// Close는 첫 stage를 닫는다. 하위 stage는 상위 stage가 모두 처리를 마치면 Run에서 차례로 닫힌다.
+func (p *Pipeline[T]) Close() {
+	p.head.Close()
+}
+
+func (p *Pipeline[T]) Shutdown(ctx context.Context) error {
+	p.Close()
+	select {
+	case <-p.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ForceClose는 모든 stage를 상위부터 강제 종료하고 첫 stage에서 처리되지 않은 이벤트를 반환한다.
+// 하위 stage에 남아 있던 이벤트는 타입이 달라 반환할 수 없으므로 버려지고 각 stage의 Stats에 Dropped로 집계된다.
+func (p *Pipeline[T]) ForceClose() []T {
+	events := p.head.ForceClose()
+	for _, st := range p.stages() {
+		if st.upstream() != nil {
+			st.forceClose()
+		}
+	}
+	return events
+}
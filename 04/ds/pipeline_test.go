@@ -0,0 +1,176 @@
+package ds
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestPipeline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[string]{}
+	parse := NewStage(4, 8, func(s string) (int, bool) {
+		n, err := strconv.Atoi(s)
+		return n, err == nil
+	})
+	double := Then(parse, 1, 8, func(n int) (int, bool) {
+		time.Sleep(time.Microsecond)
+		return n * 2, true
+	})
+	Sink(double, 1, 8, func(n int) {
+		r.handle(strconv.Itoa(n))
+	})
+
+	p, err := NewPipeline(parse)
+	require.NoError(t, err)
+	go p.Run()
+
+	for i := range 100 {
+		require.NoError(t, p.Send(strconv.Itoa(i)))
+	}
+	require.NoError(t, p.Send("x"))
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	require.Len(t, r.get(), 100)
+	require.Equal(t, uint64(100), double.Stats().Handled)
+	require.ErrorIs(t, p.Send("1"), ErrAlreadyClosedLoop)
+}
+
+func TestPipelineFanOut(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	a, b := &recorder[int]{}, &recorder[int]{}
+	head := NewStage(1, 4, func(n int) (int, bool) { return n, true })
+	Sink(head, 1, 4, a.handle)
+	Sink(head, 1, 4, b.handle)
+
+	p, err := NewPipeline(head)
+	require.NoError(t, err)
+	go p.Run()
+	for i := range 10 {
+		require.NoError(t, p.Send(i))
+	}
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, a.get())
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, b.get())
+}
+
+func TestPipelineBackpressure(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	head := NewStage(1, 1, func(n int) (int, bool) { return n, true })
+	Sink(head, 1, 1, func(int) { <-block })
+
+	p, err := NewPipeline(head)
+	require.NoError(t, err)
+	go p.Run()
+
+	// sink 처리 중 1개, sink queue 1개, head 처리 중 1개, head queue 1개
+	for i := range 4 {
+		require.NoError(t, p.SendTimeout(i, time.Second))
+	}
+	require.ErrorIs(t, p.SendTimeout(4, 10*time.Millisecond), ErrSendTimeout)
+
+	close(block)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPipelineForceClose(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	head := NewStage(1, 4, func(n int) (int, bool) {
+		if n == 0 {
+			<-block
+		}
+		return n, true
+	})
+	r := &recorder[int]{}
+	Sink(head, 1, 4, r.handle)
+
+	p, err := NewPipeline(head)
+	require.NoError(t, err)
+	go p.Run()
+	for i := range 3 {
+		require.NoError(t, p.Send(i))
+	}
+	require.Eventually(t, func() bool {
+		return head.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(block)
+	}()
+	require.Equal(t, []int{1, 2}, p.ForceClose())
+	<-p.Done()
+}
+
+func TestPipelineNotHead(t *testing.T) {
+	head := NewStage(1, 1, func(n int) (int, bool) { return n, true })
+	mid := Then(head, 1, 1, func(n int) (int, bool) { return n, true })
+
+	_, err := NewPipeline(mid)
+	require.ErrorIs(t, err, ErrNotHeadStage)
+}
+
+func TestPipelineEmitError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	r := &recorder[int]{}
+	head := NewStage(1, 4, func(n int) (int, bool) { return n, true }, WithOnError[int](func(n int, err error) {
+		require.ErrorIs(t, err, ErrQueueFull)
+		r.handle(n)
+	}))
+	sink := Sink(head, 1, 1, func(int) { <-block }, WithOverflowPolicy[int](OverflowDropNewest))
+
+	p, err := NewPipeline(head)
+	require.NoError(t, err)
+	go p.Run()
+
+	require.NoError(t, p.Send(0))
+	require.Eventually(t, func() bool {
+		return sink.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, p.Send(1))
+	require.NoError(t, p.Send(2))
+	require.Eventually(t, func() bool {
+		return len(r.get()) == 1
+	}, time.Second, time.Millisecond)
+	require.Equal(t, []int{2}, r.get())
+	require.Equal(t, uint64(1), sink.Stats().Dropped)
+
+	close(block)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestPipelineForceCloseDownstream(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	head := NewStage(1, 4, func(n int) (int, bool) { return n, true })
+	sink := Sink(head, 1, 4, func(int) { <-block })
+
+	p, err := NewPipeline(head)
+	require.NoError(t, err)
+	go p.Run()
+	for i := range 4 {
+		require.NoError(t, p.Send(i))
+	}
+	require.Eventually(t, func() bool {
+		return sink.Stats().QueueLen == 3
+	}, time.Second, time.Millisecond)
+
+	require.Empty(t, p.ForceClose())
+	require.Equal(t, uint64(3), sink.Stats().Dropped)
+	close(block)
+	<-p.Done()
+}
@@ -0,0 +1,158 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Prioritizer를 구현한 이벤트는 Send 시 Priority()의 값을 우선순위로 사용한다.
+type Prioritizer interface {
+	Priority() int
+}
+
+// PriorityEventloop은 우선순위가 높은 이벤트를 먼저 처리하는 Eventloop이다.
+// 우선순위는 0부터 levels-1까지이며 값이 클수록 먼저 처리된다.
+// queue만 우선순위 queue로 바뀐 Eventloop이므로 Eventloop의 메서드와 옵션을 모두 사용할 수 있다.
+type PriorityEventloop[T any] struct {
+	*Eventloop[T]
+	starvationLimit int
+	loopOpts        []EventloopOption[T]
+}
+
+type PriorityOption[T any] func(*PriorityEventloop[T])
+
+// WithStarvationLimit는 더 높은 우선순위 때문에 limit번 밀린 이벤트를 다음에 처리하도록 한다.
+// 0이면 starvation guard를 사용하지 않는다.
+func WithStarvationLimit[T any](limit int) PriorityOption[T] {
+	return func(p *PriorityEventloop[T]) {
+		p.starvationLimit = limit
+	}
+}
+
+// WithPriorityLoopOptions는 내부 Eventloop에 적용할 옵션을 지정한다.
+// WithQueue, WithRingQueue처럼 queue를 바꾸는 옵션은 무시된다.
+func WithPriorityLoopOptions[T any](opts ...EventloopOption[T]) PriorityOption[T] {
+	return func(p *PriorityEventloop[T]) {
+		p.loopOpts = append(p.loopOpts, opts...)
+	}
+}
+
+// NewPriorityEventloop은 levels가 1보다 작으면 1로, dispatchCount는 NewEventloop과 같이 1 이상으로 맞춘다.
+func NewPriorityEventloop[T any](dispatchCount, queueSize, levels int, handler func(T), opts ...PriorityOption[T]) *PriorityEventloop[T] {
+	p := &PriorityEventloop[T]{starvationLimit: 8}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	queue := newPriorityQueue[T](max(queueSize, 1), max(levels, 1), p.starvationLimit)
+	loopOpts := append(p.loopOpts, func(e *Eventloop[T]) {
+		e.queue = queue
+	})
+	p.Eventloop = NewEventloop(dispatchCount, queueSize, handler, loopOpts...)
+	return p
+}
+
+// SendPriority는 이벤트가 Prioritizer를 구현했더라도 priority로 이벤트를 보낸다.
+func (p *PriorityEventloop[T]) SendPriority(event T, priority int) error {
+	return p.SendPriorityContext(context.Background(), event, priority)
+}
+
+func (p *PriorityEventloop[T]) SendPriorityContext(ctx context.Context, event T, priority int) error {
+	env := p.wrap(ctx, event)
+	env.priority = priority
+	return p.send(ctx, env, true, nil)
+}
+
+func (p *PriorityEventloop[T]) Len() int {
+	return p.queue.Len()
+}
+
+func priorityOf[T any](event T) int {
+	if prioritizer, ok := any(event).(Prioritizer); ok {
+		return prioritizer.Priority()
+	}
+	return 0
+}
+
+// priorityQueue는 우선순위별 FIFO를 mutex로 보호하는 Queue다.
+type priorityQueue[T any] struct {
+	mu              sync.Mutex
+	levels          [][]Envelope[T]
+	skipped         []int
+	starvationLimit int
+	// size는 Stats가 mutex를 잡지 않도록 atomic으로 관리한다.
+	size     atomic.Int64
+	capacity int
+}
+
+func newPriorityQueue[T any](capacity, levels, starvationLimit int) *priorityQueue[T] {
+	return &priorityQueue[T]{
+		levels:          make([][]Envelope[T], levels),
+		skipped:         make([]int, levels),
+		starvationLimit: starvationLimit,
+		capacity:        capacity,
+	}
+}
+
+// TryPush는 범위를 벗어난 우선순위를 가장 가까운 level로 맞춘다.
+func (q *priorityQueue[T]) TryPush(env Envelope[T]) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if int(q.size.Load()) >= q.capacity {
+		return false
+	}
+
+	priority := min(max(env.priority, 0), len(q.levels)-1)
+	q.levels[priority] = append(q.levels[priority], env)
+	q.size.Add(1)
+	return true
+}
+
+// TryPop은 가장 높은 우선순위의 이벤트를 꺼낸다.
+// 단, starvationLimit번 이상 밀린 우선순위가 있으면 그 중 가장 낮은 우선순위의 이벤트를 먼저 꺼낸다.
+func (q *priorityQueue[T]) TryPop() (Envelope[T], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size.Load() == 0 {
+		return Envelope[T]{}, false
+	}
+
+	chosen := -1
+	for i := len(q.levels) - 1; i >= 0; i-- {
+		if len(q.levels[i]) > 0 {
+			chosen = i
+			break
+		}
+	}
+
+	if q.starvationLimit > 0 {
+		for i := 0; i < chosen; i++ {
+			if len(q.levels[i]) > 0 && q.skipped[i] >= q.starvationLimit {
+				chosen = i
+				break
+			}
+		}
+	}
+
+	for i := range q.levels {
+		if i != chosen && len(q.levels[i]) > 0 {
+			q.skipped[i]++
+		}
+	}
+	q.skipped[chosen] = 0
+
+	env := q.levels[chosen][0]
+	q.levels[chosen][0] = Envelope[T]{}
+	q.levels[chosen] = q.levels[chosen][1:]
+	q.size.Add(-1)
+	return env, true
+}
+
+func (q *priorityQueue[T]) Len() int {
+	return int(q.size.Load())
+}
+
+func (q *priorityQueue[T]) Cap() int {
+	return q.capacity
+}
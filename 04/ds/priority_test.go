@@ -0,0 +1,110 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type priorityEvent struct {
+	name     string
+	priority int
+}
+
+func (p priorityEvent) Priority() int {
+	return p.priority
+}
+
+func TestPriorityEventloopOrder(t *testing.T) {
+	handler := func(event string) {}
+	el := NewPriorityEventloop(1, 16, 3, handler, WithStarvationLimit[string](0))
+
+	require.NoError(t, el.SendPriority("low1", 0))
+	require.NoError(t, el.SendPriority("high1", 2))
+	require.NoError(t, el.SendPriority("low2", 0))
+	require.NoError(t, el.SendPriority("mid1", 1))
+	require.NoError(t, el.SendPriority("high2", 5))
+	require.NoError(t, el.SendPriority("low3", -1))
+	require.Equal(t, 6, el.Len())
+
+	require.Equal(t, []string{"high1", "high2", "mid1", "low1", "low2", "low3"}, el.ForceClose())
+}
+
+func TestPriorityEventloopStarvation(t *testing.T) {
+	handler := func(event string) {}
+	el := NewPriorityEventloop(1, 16, 2, handler, WithStarvationLimit[string](2))
+
+	require.NoError(t, el.SendPriority("low", 0))
+	for _, name := range []string{"h1", "h2", "h3", "h4"} {
+		require.NoError(t, el.SendPriority(name, 1))
+	}
+
+	// low는 두 번 밀린 뒤 처리되어야 함
+	require.Equal(t, []string{"h1", "h2", "low", "h3", "h4"}, el.ForceClose())
+}
+
+func TestPriorityEventloopPrioritizer(t *testing.T) {
+	var mu sync.Mutex
+	var processed []string
+	handler := func(event priorityEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, event.name)
+	}
+	el := NewPriorityEventloop(1, 2, 2, handler)
+
+	require.NoError(t, el.Send(priorityEvent{name: "data", priority: 0}))
+	require.NoError(t, el.Send(priorityEvent{name: "reload", priority: 1}))
+	require.ErrorIs(t, el.TrySend(priorityEvent{name: "data2"}), ErrQueueFull)
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.ErrorIs(t, el.Send(priorityEvent{name: "late"}), ErrAlreadyClosedLoop)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"reload", "data"}, processed)
+}
+
+func TestPriorityEventloopInvalidArgs(t *testing.T) {
+	var mu sync.Mutex
+	var processed []string
+	handler := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, event)
+	}
+	el := NewPriorityEventloop(0, 4, -1, handler)
+
+	require.NoError(t, el.SendPriority("a", 3))
+	require.NoError(t, el.SendPriority("b", 0))
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"a", "b"}, processed)
+}
+
+func TestPriorityEventloopLoopOptions(t *testing.T) {
+	var dropped []string
+	handler := func(event string) {}
+	el := NewPriorityEventloop(1, 1, 2, handler, WithPriorityLoopOptions(
+		WithOverflowCallback(func(event string) {
+			dropped = append(dropped, event)
+		}),
+	))
+
+	require.NoError(t, el.SendPriority("a", 1))
+	require.ErrorIs(t, el.SendPriority("b", 1), ErrQueueFull)
+	require.Equal(t, []string{"b"}, dropped)
+
+	stats := el.Stats()
+	require.Equal(t, uint64(1), stats.Sent)
+	require.Equal(t, uint64(1), stats.Dropped)
+	require.Equal(t, 1, stats.QueueCap)
+	require.Equal(t, []string{"a"}, el.ForceClose())
+}
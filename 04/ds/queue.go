@@ -0,0 +1,209 @@
+package ds
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// Queue는 Eventloop이 이벤트를 보관하는 크기가 고정된 FIFO다.
+// 모든 메서드는 여러 goroutine에서 동시에 호출할 수 있어야 하며 대기하지 않는다.
+// WithQueue로 Eventloop의 queue를 바꿀 수 있으며 이때 T는 Envelope이다.
+type Queue[T any] interface {
+	TryPush(v T) bool
+	TryPop() (T, bool)
+	Len() int
+	Cap() int
+}
+
+// ChanQueue는 buffered channel로 구현한 Queue이며 Eventloop의 기본 queue다.
+type ChanQueue[T any] struct {
+	ch chan T
+}
+
+func NewChanQueue[T any](size int) *ChanQueue[T] {
+	return &ChanQueue[T]{ch: make(chan T, size)}
+}
+
+func (q *ChanQueue[T]) TryPush(v T) bool {
+	select {
+	case q.ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *ChanQueue[T]) TryPop() (T, bool) {
+	select {
+	case v := <-q.ch:
+		return v, true
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+func (q *ChanQueue[T]) Len() int {
+	return len(q.ch)
+}
+
+func (q *ChanQueue[T]) Cap() int {
+	return cap(q.ch)
+}
+
+// RingQueue는 Dmitry Vyukov의 bounded MPMC queue를 구현한 lock-free ring buffer다.
+// 각 cell의 seq로 쓰기와 읽기 차례를 구분하므로 producer와 consumer가 서로 다른 cell에서 경쟁 없이 동작한다.
+// 크기는 2 이상의 2의 거듭제곱으로 올림된다.
+type RingQueue[T any] struct {
+	_     [64]byte
+	enq   atomic.Uint64
+	_     [56]byte
+	deq   atomic.Uint64
+	_     [56]byte
+	mask  uint64
+	cells []ringCell[T]
+}
+
+type ringCell[T any] struct {
+	seq atomic.Uint64
+	v   T
+}
+
+func NewRingQueue[T any](size int) *RingQueue[T] {
+	n := uint64(2)
+	if size > 2 {
+		n = 1 << bits.Len64(uint64(size-1))
+	}
+
+	q := &RingQueue[T]{
+		mask:  n - 1,
+		cells: make([]ringCell[T], n),
+	}
+	for i := range q.cells {
+		q.cells[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// TryPush는 cell의 seq가 enq 위치와 같을 때, 즉 consumer가 비운 cell일 때만 쓴다.
+func (q *RingQueue[T]) TryPush(v T) bool {
+	pos := q.enq.Load()
+	for {
+		cell := &q.cells[pos&q.mask]
+		diff := int64(cell.seq.Load() - pos)
+		switch {
+		case diff == 0:
+			if q.enq.CompareAndSwap(pos, pos+1) {
+				cell.v = v
+				cell.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.enq.Load()
+		case diff < 0:
+			// 한 바퀴 전의 값을 아직 consumer가 꺼내지 않았으므로 가득 참
+			return false
+		default:
+			pos = q.enq.Load()
+		}
+	}
+}
+
+// TryPop은 cell의 seq가 deq+1, 즉 producer가 쓰기를 마친 cell일 때만 꺼낸다.
+func (q *RingQueue[T]) TryPop() (T, bool) {
+	var zero T
+	pos := q.deq.Load()
+	for {
+		cell := &q.cells[pos&q.mask]
+		diff := int64(cell.seq.Load() - (pos + 1))
+		switch {
+		case diff == 0:
+			if q.deq.CompareAndSwap(pos, pos+1) {
+				v := cell.v
+				cell.v = zero
+				cell.seq.Store(pos + q.mask + 1)
+				return v, true
+			}
+			pos = q.deq.Load()
+		case diff < 0:
+			return zero, false
+		default:
+			pos = q.deq.Load()
+		}
+	}
+}
+
+func (q *RingQueue[T]) Len() int {
+	deq := q.deq.Load()
+	enq := q.enq.Load()
+	if enq <= deq {
+		return 0
+	}
+	return int(min(enq-deq, q.mask+1))
+}
+
+func (q *RingQueue[T]) Cap() int {
+	return int(q.mask + 1)
+}
+
+// WithQueue는 Eventloop의 queue로 newQueue(queueSize)가 반환한 Queue를 사용한다.
+// ChanQueue가 아니면 queue가 가득 차거나 비었을 때의 대기는 신호용 channel로 처리한다.
+func WithQueue[T any](newQueue func(size int) Queue[Envelope[T]]) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.queue = newQueue(e.queueSize)
+	}
+}
+
+// WithRingQueue는 Eventloop의 queue로 channel 대신 RingQueue를 사용한다.
+func WithRingQueue[T any]() EventloopOption[T] {
+	return WithQueue(func(size int) Queue[Envelope[T]] {
+		return NewRingQueue[Envelope[T]](size)
+	})
+}
+
+// setQueue는 queue가 ChanQueue면 channel로 직접 대기하고 아니면 신호용 channel을 만든다.
+// select에서 nil channel은 선택되지 않으므로 두 경우를 같은 select로 처리할 수 있다.
+func (e *Eventloop[T]) setQueue() {
+	if q, ok := e.queue.(*ChanQueue[Envelope[T]]); ok {
+		e.pushCh, e.popCh = q.ch, q.ch
+		return
+	}
+	e.notEmpty = make(chan struct{}, 1)
+	e.notFull = make(chan struct{}, 1)
+}
+
+// push는 대기하지 않고 queue에 넣는다.
+// 대기 중인 consumer를 깨우고 자리가 남아 있으면 다른 producer도 깨운다.
+func (e *Eventloop[T]) push(env Envelope[T]) bool {
+	ok := e.queue.TryPush(env)
+	if e.notEmpty != nil {
+		if ok {
+			signal(e.notEmpty)
+		}
+		if e.queue.Len() < e.queue.Cap() {
+			signal(e.notFull)
+		}
+	}
+	return ok
+}
+
+// pop은 대기하지 않고 queue에서 꺼낸다.
+// 대기 중인 producer를 깨우고 이벤트가 남아 있으면 다른 consumer도 깨운다.
+func (e *Eventloop[T]) pop() (Envelope[T], bool) {
+	env, ok := e.queue.TryPop()
+	if e.notEmpty != nil {
+		if ok {
+			signal(e.notFull)
+		}
+		if e.queue.Len() > 0 {
+			signal(e.notEmpty)
+		}
+	}
+	return env, ok
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,321 @@
+package ds
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRingQueue(t *testing.T) {
+	require.Equal(t, 2, NewRingQueue[int](0).Cap())
+	require.Equal(t, 2, NewRingQueue[int](2).Cap())
+	require.Equal(t, 8, NewRingQueue[int](5).Cap())
+	require.Equal(t, 8, NewRingQueue[int](8).Cap())
+
+	q := NewRingQueue[int](4)
+	_, ok := q.TryPop()
+	require.False(t, ok)
+
+	for round := range 3 {
+		for i := range 4 {
+			require.True(t, q.TryPush(round*10+i))
+		}
+		require.False(t, q.TryPush(-1))
+		require.Equal(t, 4, q.Len())
+
+		for i := range 4 {
+			v, ok := q.TryPop()
+			require.True(t, ok)
+			require.Equal(t, round*10+i, v)
+		}
+		_, ok = q.TryPop()
+		require.False(t, ok)
+		require.Zero(t, q.Len())
+	}
+}
+
+func TestRingQueueConcurrent(t *testing.T) {
+	const producers, consumers, perProducer = 8, 8, 10000
+
+	q := NewRingQueue[int](64)
+	var sum, count atomic.Int64
+	wg := sync.WaitGroup{}
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perProducer {
+				for !q.TryPush(p*perProducer + i + 1) {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	cwg := sync.WaitGroup{}
+	for range consumers {
+		cwg.Add(1)
+		go func() {
+			defer cwg.Done()
+			for {
+				if v, ok := q.TryPop(); ok {
+					sum.Add(int64(v))
+					count.Add(1)
+					continue
+				}
+				select {
+				case <-done:
+					if q.Len() == 0 {
+						return
+					}
+				default:
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	cwg.Wait()
+
+	n := int64(producers * perProducer)
+	require.Equal(t, n, count.Load())
+	require.Equal(t, n*(n+1)/2, sum.Load())
+}
+
+func TestEventloopRingQueue(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var handled atomic.Int64
+	el := NewEventloop(4, 4, func(int) {
+		handled.Add(1)
+	}, WithRingQueue[int]())
+	require.Equal(t, 4, el.Stats().QueueCap)
+	go el.Run()
+
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				require.NoError(t, el.Send(i))
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, int64(8000), handled.Load())
+}
+
+func TestEventloopRingQueueBlocking(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	r := &recorder[int]{}
+	el := NewEventloop(1, 2, func(event int) {
+		<-block
+		r.handle(event)
+	}, WithRingQueue[int]())
+	go el.Run()
+
+	require.NoError(t, el.Send(0))
+	require.Eventually(t, func() bool {
+		return el.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(2))
+	require.ErrorIs(t, el.TrySend(3), ErrQueueFull)
+	require.ErrorIs(t, el.SendTimeout(3, 10*time.Millisecond), ErrSendTimeout)
+
+	sent := make(chan error)
+	go func() {
+		sent <- el.Send(3)
+	}()
+	close(block)
+	require.NoError(t, <-sent)
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{0, 1, 2, 3}, r.get())
+}
+
+func TestEventloopRingQueueForceClose(t *testing.T) {
+	el := NewEventloop(1, 4, func(int) {}, WithRingQueue[int](), WithOverflowPolicy[int](OverflowDropOldest))
+	for i := range 6 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Equal(t, uint64(2), el.Stats().Dropped)
+	require.Equal(t, []int{2, 3, 4, 5}, el.ForceClose())
+}
+
+func TestBatchEventloopRingQueue(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[[]int]{}
+	el := NewBatchEventloop(1, 16, 4, time.Hour, r.handle, WithRingQueue[int]())
+	for i := range 10 {
+		require.NoError(t, el.Send(i))
+	}
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}}, r.get())
+}
+
+var benchQueueSizes = []struct{ producers, consumers int }{
+	{1, 1}, {4, 4}, {8, 1}, {1, 8}, {16, 16},
+}
+
+// stackQueue는 WithQueue를 확인하기 위한 LIFO Queue다.
+type stackQueue[V any] struct {
+	mu    sync.Mutex
+	items []V
+	size  int
+}
+
+func (q *stackQueue[V]) TryPush(v V) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) >= q.size {
+		return false
+	}
+	q.items = append(q.items, v)
+	return true
+}
+
+func (q *stackQueue[V]) TryPop() (V, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var zero V
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	v := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return v, true
+}
+
+func (q *stackQueue[V]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+func (q *stackQueue[V]) Cap() int {
+	return q.size
+}
+
+func TestEventloopWithQueue(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[int]{}
+	el := NewEventloop(1, 3, r.handle, WithQueue(func(size int) Queue[Envelope[int]] {
+		return &stackQueue[Envelope[int]]{size: size}
+	}))
+	for i := range 3 {
+		require.NoError(t, el.Send(i))
+	}
+	require.ErrorIs(t, el.TrySend(3), ErrQueueFull)
+	require.Equal(t, 3, el.Stats().QueueCap)
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{2, 1, 0}, r.get())
+}
+
+func BenchmarkQueue(b *testing.B) {
+	backends := []struct {
+		name string
+		new  func() Queue[int]
+	}{
+		{"chan", func() Queue[int] { return NewChanQueue[int](1024) }},
+		{"ring", func() Queue[int] { return NewRingQueue[int](1024) }},
+	}
+	for _, backend := range backends {
+		for _, size := range benchQueueSizes {
+			b.Run(fmt.Sprintf("%s/p%d-c%d", backend.name, size.producers, size.consumers), func(b *testing.B) {
+				benchmarkQueue(b, backend.new(), size.producers, size.consumers)
+			})
+		}
+	}
+}
+
+func benchmarkQueue(b *testing.B, q Queue[int], producers, consumers int) {
+	var remaining atomic.Int64
+	remaining.Store(int64(b.N))
+	wg := sync.WaitGroup{}
+
+	b.ResetTimer()
+	for p := range producers {
+		n := b.N / producers
+		if p < b.N%producers {
+			n++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range n {
+				for !q.TryPush(i) {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	for range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for remaining.Load() > 0 {
+				if _, ok := q.TryPop(); ok {
+					remaining.Add(-1)
+					continue
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkEventloopQueue(b *testing.B) {
+	backends := []struct {
+		name string
+		opts []EventloopOption[int]
+	}{
+		{"chan", nil},
+		{"ring", []EventloopOption[int]{WithRingQueue[int]()}},
+	}
+	for _, backend := range backends {
+		for _, size := range benchQueueSizes {
+			b.Run(fmt.Sprintf("%s/p%d-c%d", backend.name, size.producers, size.consumers), func(b *testing.B) {
+				el := NewEventloop(size.consumers, 1024, func(int) {}, backend.opts...)
+				go el.Run()
+
+				b.ResetTimer()
+				wg := sync.WaitGroup{}
+				for p := range size.producers {
+					n := b.N / size.producers
+					if p < b.N%size.producers {
+						n++
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for i := range n {
+							_ = el.Send(i)
+						}
+					}()
+				}
+				wg.Wait()
+				_ = el.Shutdown(context.Background())
+			})
+		}
+	}
+}
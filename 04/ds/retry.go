@@ -0,0 +1,85 @@
+package ds
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+type RetryPolicy struct {
+	// MaxAttempts는 첫 실행을 포함한 최대 실행 횟수다. 1 이하이면 재시도하지 않는다.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Multiplier가 1 이하이면 2를 사용한다.
+	Multiplier float64
+	// Jitter는 backoff에서 무작위로 줄일 비율로 0과 1 사이의 값이다.
+	Jitter float64
+	// Retryable이 nil이면 모든 에러를 재시도한다.
+	Retryable func(error) bool
+}
+
+func (p RetryPolicy) shouldRetry(err error, attempts int) bool {
+	if attempts >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+
+	// MaxBackoff가 없어도 time.Duration을 넘지 않도록 제한함
+	limit := float64(math.MaxInt64)
+	if p.MaxBackoff > 0 {
+		limit = float64(p.MaxBackoff)
+	}
+
+	d := float64(p.InitialBackoff)
+	for range attempts - 1 {
+		d *= multiplier
+		if d >= limit {
+			d = limit
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		d -= d * min(p.Jitter, 1) * rand.Float64()
+	}
+	// float64(math.MaxInt64)는 2^63으로 올림되므로 그대로 변환하면 음수가 됨
+	if d >= float64(math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// DeadLetter는 재시도를 모두 소진한 이벤트와 마지막 에러, 실행 횟수를 담는다.
+type DeadLetter[T any] struct {
+	Event    T
+	Err      error
+	Attempts int
+}
+
+// DeadLetterToLoop은 dead letter를 el로 보내며 el이 종료되었거나 overflow 정책으로 버려지면 Send의 에러를 반환한다.
+func DeadLetterToLoop[T any](el *Eventloop[DeadLetter[T]]) func(DeadLetter[T]) error {
+	return func(d DeadLetter[T]) error {
+		return el.Send(d)
+	}
+}
+
+// DeadLetterToChan은 dead letter를 ch로 보낸다.
+// dispatcher가 멈추지 않도록 대기하지 않으며 ch가 가득 찼으면 ErrQueueFull을 반환한다.
+func DeadLetterToChan[T any](ch chan<- DeadLetter[T]) func(DeadLetter[T]) error {
+	return func(d DeadLetter[T]) error {
+		select {
+		case ch <- d:
+			return nil
+		default:
+			return ErrQueueFull
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	}
+	require.Equal(t, 10*time.Millisecond, p.backoff(1))
+	require.Equal(t, 20*time.Millisecond, p.backoff(2))
+	require.Equal(t, 40*time.Millisecond, p.backoff(3))
+	require.Equal(t, 50*time.Millisecond, p.backoff(4))
+	require.Equal(t, 50*time.Millisecond, p.backoff(10))
+
+	p.Jitter = 0.5
+	for range 100 {
+		d := p.backoff(2)
+		require.LessOrEqual(t, d, 20*time.Millisecond)
+		require.GreaterOrEqual(t, d, 10*time.Millisecond)
+	}
+
+	// MaxBackoff가 없으면 time.Duration의 최댓값에서 멈춰야 함
+	p = RetryPolicy{InitialBackoff: time.Second}
+	require.Equal(t, time.Duration(math.MaxInt64), p.backoff(40))
+	require.Equal(t, time.Duration(math.MaxInt64), p.backoff(1000))
+}
+
+func TestEventloopRetry(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[int]int)
+	handler := func(event int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[event]++
+		// 짝수 이벤트는 세 번째 실행에서 성공
+		if event%2 == 0 && attempts[event] == 3 {
+			return nil
+		}
+		return errors.New("fail")
+	}
+
+	dlq := make(chan DeadLetter[int], 10)
+	el := NewEventloopWithError(2, 1024, handler,
+		WithRetry[int](RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		WithDeadLetter(DeadLetterToChan(dlq)),
+	)
+
+	go el.Run()
+	for i := range 4 {
+		require.NoError(t, el.Send(i))
+	}
+	el.Close()
+	<-el.Done()
+	close(dlq)
+
+	var dead []int
+	for d := range dlq {
+		require.Equal(t, 3, d.Attempts)
+		require.EqualError(t, d.Err, "fail")
+		dead = append(dead, d.Event)
+	}
+	require.ElementsMatch(t, []int{1, 3}, dead)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, map[int]int{0: 3, 1: 3, 2: 3, 3: 3}, attempts)
+}
+
+func TestEventloopRetryNotRetryable(t *testing.T) {
+	errFatal := errors.New("fatal")
+	calls := atomic.Int64{}
+	handler := func(event int) error {
+		calls.Add(1)
+		return errFatal
+	}
+
+	var mu sync.Mutex
+	var dead []DeadLetter[int]
+	dlq := NewEventloop(1, 16, func(d DeadLetter[int]) {
+		mu.Lock()
+		defer mu.Unlock()
+		dead = append(dead, d)
+	})
+	go dlq.Run()
+
+	el := NewEventloopWithError(1, 16, handler,
+		WithRetry[int](RetryPolicy{
+			MaxAttempts: 5,
+			Retryable: func(err error) bool {
+				return !errors.Is(err, errFatal)
+			},
+		}),
+		WithDeadLetter(DeadLetterToLoop(dlq)),
+	)
+	go el.Run()
+
+	require.NoError(t, el.Send(7))
+	el.Close()
+	<-el.Done()
+	dlq.Close()
+	<-dlq.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, int64(1), calls.Load())
+	require.Equal(t, []DeadLetter[int]{{Event: 7, Err: errFatal, Attempts: 1}}, dead)
+}
+
+func TestEventloopRetryForceClose(t *testing.T) {
+	handler := func(event int) error {
+		return errors.New("fail")
+	}
+
+	dlq := make(chan DeadLetter[int], 1)
+	el := NewEventloopWithError(1, 16, handler,
+		WithRetry[int](RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}),
+		WithDeadLetter(DeadLetterToChan(dlq)),
+	)
+	go el.Run()
+
+	require.NoError(t, el.Send(1))
+	time.Sleep(10 * time.Millisecond)
+	el.ForceClose()
+	<-el.Done()
+
+	// 강제 종료 시 backoff 대기 중인 이벤트는 dead letter로 전달되어야 함
+	d := <-dlq
+	require.Equal(t, 1, d.Event)
+	require.Equal(t, 1, d.Attempts)
+}
+
+func TestEventloopDeadLetterFailed(t *testing.T) {
+	handler := func(event int) error {
+		return errors.New("fail")
+	}
+
+	dlq := NewEventloop(1, 1, func(DeadLetter[int]) {})
+	dlq.Close()
+
+	var errs []error
+	el := NewEventloopWithError(1, 16, handler,
+		WithDeadLetter(DeadLetterToLoop(dlq)),
+		WithOnError(func(event int, err error) {
+			errs = append(errs, err)
+		}),
+	)
+	go el.Run()
+
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	// 처리 실패와 dead letter 전달 실패가 모두 보고되어야 함
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[1], ErrDeadLetter)
+	require.ErrorIs(t, errs[1], ErrAlreadyClosedLoop)
+	require.Equal(t, uint64(1), el.Stats().DeadLetterFailed)
+}
+
+func TestDeadLetterToChanFull(t *testing.T) {
+	handler := func(event int) error {
+		return errors.New("fail")
+	}
+
+	var errs []error
+	dlq := make(chan DeadLetter[int])
+	el := NewEventloopWithError(1, 16, handler,
+		WithDeadLetter(DeadLetterToChan(dlq)),
+		WithOnError(func(event int, err error) {
+			errs = append(errs, err)
+		}),
+	)
+	go el.Run()
+
+	// 아무도 읽지 않는 channel 때문에 dispatcher가 멈추지 않아야 함
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[1], ErrDeadLetter)
+	require.ErrorIs(t, errs[1], ErrQueueFull)
+	require.Equal(t, uint64(1), el.Stats().DeadLetterFailed)
+}
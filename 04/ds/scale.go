@@ -0,0 +1,157 @@
+package ds
+
+import "time"
+
+type AutoscaleConfig struct {
+	Min int
+	Max int
+	// Interval마다 queue 길이와 handler 평균 latency를 확인한다.
+	Interval time.Duration
+	// QueueThreshold 이상 이벤트가 쌓이면 dispatcher를 추가한다. 0이면 확인하지 않는다.
+	QueueThreshold int
+	// LatencyThreshold 이상으로 handler 평균 latency가 늘어나면 dispatcher를 추가한다. 0이면 확인하지 않는다.
+	LatencyThreshold time.Duration
+	// IdleTimeout 동안 이벤트를 받지 못한 dispatcher는 Min개까지 종료된다. 0이면 종료하지 않는다.
+	IdleTimeout time.Duration
+}
+
+func WithAutoscale[T any](cfg AutoscaleConfig) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		cfg.Min = max(cfg.Min, 1)
+		cfg.Max = max(cfg.Max, cfg.Min)
+		if cfg.Interval <= 0 {
+			cfg.Interval = 100 * time.Millisecond
+		}
+		e.autoscale = &cfg
+		e.target.Store(int32(min(max(int(e.target.Load()), cfg.Min), cfg.Max)))
+	}
+}
+
+// Resize는 dispatcher 수를 n으로 변경한다.
+// 줄어드는 경우 dispatcher는 처리 중인 이벤트를 끝낸 뒤 종료된다.
+// WithAutoscale을 사용하면 n은 AutoscaleConfig의 Min과 Max 사이로 맞춰지며 이후 autoscale이 다시 조정한다.
+func (e *Eventloop[T]) Resize(n int) {
+	e.scaleMu.Lock()
+	defer e.scaleMu.Unlock()
+	e.resize(n)
+}
+
+func (e *Eventloop[T]) resize(n int) {
+	if e.closed.Load() {
+		return
+	}
+
+	n = max(n, 1)
+	if e.autoscale != nil {
+		n = min(max(n, e.autoscale.Min), e.autoscale.Max)
+	}
+	e.target.Store(int32(n))
+	if !e.running {
+		return
+	}
+
+	for e.active.Load() < e.target.Load() {
+		e.spawn()
+	}
+	e.notify()
+}
+
+// retire는 dispatcher 수가 목표보다 많으면 active를 줄이고 호출한 dispatcher를 종료 대상으로 만든다.
+func (e *Eventloop[T]) retire() bool {
+	for {
+		active := e.active.Load()
+		if active <= e.target.Load() {
+			return false
+		}
+		if e.active.CompareAndSwap(active, active-1) {
+			return true
+		}
+	}
+}
+
+// shrinkIdle은 IdleTimeout 동안 쉬고 있던 dispatcher가 호출하여 목표 dispatcher 수를 하나 줄인다.
+func (e *Eventloop[T]) shrinkIdle() {
+	e.scaleMu.Lock()
+	defer e.scaleMu.Unlock()
+
+	if target := int(e.target.Load()); target > e.autoscale.Min {
+		e.resize(target - 1)
+	}
+}
+
+func (e *Eventloop[T]) runAutoscale() {
+	ticker := time.NewTicker(e.autoscale.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.idleCh:
+			return
+		}
+
+		sum := e.latencySum.Swap(0)
+		count := e.latencyCount.Swap(0)
+
+		// 멈춘 동안 쌓이는 이벤트로는 dispatcher를 늘리지 않음
+		if e.paused.Load() {
+			continue
+		}
+
+		busy := e.autoscale.QueueThreshold > 0 && e.queue.Len() >= e.autoscale.QueueThreshold
+		if e.autoscale.LatencyThreshold > 0 && count > 0 && time.Duration(sum/count) >= e.autoscale.LatencyThreshold {
+			busy = true
+		}
+		if !busy {
+			continue
+		}
+
+		e.scaleMu.Lock()
+		if target := int(e.target.Load()); target < e.autoscale.Max {
+			e.resize(target + 1)
+		}
+		e.scaleMu.Unlock()
+	}
+}
+
+type dispatcher struct {
+	timeout time.Duration
+	timer   *time.Timer
+	retired bool
+}
+
+func (e *Eventloop[T]) newDispatcher() *dispatcher {
+	d := &dispatcher{}
+	if e.autoscale != nil && e.autoscale.IdleTimeout > 0 {
+		d.timeout = e.autoscale.IdleTimeout
+		d.timer = time.NewTimer(d.timeout)
+	}
+	return d
+}
+
+func (d *dispatcher) idle() <-chan time.Time {
+	if d.timer == nil {
+		return nil
+	}
+	return d.timer.C
+}
+
+// reset은 이벤트를 받은 뒤 idle timer를 다시 시작한다.
+func (d *dispatcher) reset() {
+	if d.timer == nil {
+		return
+	}
+	if !d.timer.Stop() {
+		select {
+		case <-d.timer.C:
+		default:
+		}
+	}
+	d.timer.Reset(d.timeout)
+}
+
+func (d *dispatcher) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
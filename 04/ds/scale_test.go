@@ -0,0 +1,115 @@
+package ds
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestEventloopResize(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	block := make(chan struct{})
+	running := atomic.Int64{}
+	handler := func(event int) {
+		running.Add(1)
+		defer running.Add(-1)
+		<-block
+	}
+	el := NewEventloop(1, 1024, handler)
+	el.Resize(2)
+
+	go el.Run()
+	require.Eventually(t, func() bool {
+		return el.Stats().Dispatchers == 2
+	}, time.Second, time.Millisecond)
+
+	for i := range 8 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Eventually(t, func() bool {
+		return running.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	el.Resize(4)
+	require.Equal(t, 4, el.Stats().Dispatchers)
+	require.Eventually(t, func() bool {
+		return running.Load() == 4
+	}, time.Second, time.Millisecond)
+
+	// 처리 중인 이벤트가 끝난 뒤 dispatcher가 줄어들어야 함
+	el.Resize(1)
+	close(block)
+	require.Eventually(t, func() bool {
+		return el.Stats().Dispatchers == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, 0, el.Stats().Dispatchers)
+}
+
+func TestEventloopAutoscale(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	handler := func(event int) {
+		time.Sleep(time.Millisecond)
+	}
+	el := NewEventloop(1, 1024, handler, WithAutoscale[int](AutoscaleConfig{
+		Min:            1,
+		Max:            4,
+		Interval:       2 * time.Millisecond,
+		QueueThreshold: 10,
+		IdleTimeout:    20 * time.Millisecond,
+	}))
+
+	go el.Run()
+
+	maxDispatchers := 0
+	for i := range 500 {
+		require.NoError(t, el.Send(i))
+		maxDispatchers = max(maxDispatchers, el.Stats().Dispatchers)
+	}
+	require.Eventually(t, func() bool {
+		maxDispatchers = max(maxDispatchers, el.Stats().Dispatchers)
+		return el.Stats().QueueLen == 0
+	}, 5*time.Second, time.Millisecond)
+	require.Equal(t, 4, maxDispatchers)
+
+	// 이벤트가 없으면 Min까지 줄어들어야 함
+	require.Eventually(t, func() bool {
+		return el.Stats().Dispatchers == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, el.Shutdown(context.Background()))
+}
+
+func TestEventloopResizeAutoscaleBounds(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	el := NewEventloop(1, 16, func(event int) {}, WithAutoscale[int](AutoscaleConfig{
+		Min:      2,
+		Max:      3,
+		Interval: time.Hour,
+	}))
+	go el.Run()
+
+	require.Eventually(t, func() bool {
+		return el.Stats().Dispatchers == 2
+	}, time.Second, time.Millisecond)
+
+	el.Resize(10)
+	require.Eventually(t, func() bool {
+		return el.Stats().Dispatchers == 3
+	}, time.Second, time.Millisecond)
+
+	el.Resize(0)
+	require.Eventually(t, func() bool {
+		return el.Stats().Dispatchers == 2
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, el.Shutdown(context.Background()))
+}
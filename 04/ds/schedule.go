@@ -0,0 +1,201 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+type ScheduleClosePolicy int
+
+const (
+	// ScheduleWait는 예약된 이벤트가 모두 전달될 때까지 Close의 종료를 미룬다.
+	ScheduleWait ScheduleClosePolicy = iota
+	// ScheduleFire는 Close 시 예약된 이벤트를 예약 시각 순서대로 즉시 전달한다.
+	ScheduleFire
+	// ScheduleDrop은 Close 시 예약된 이벤트를 버린다.
+	ScheduleDrop
+)
+
+func WithScheduleClosePolicy[T any](policy ScheduleClosePolicy) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.schedulePolicy = policy
+	}
+}
+
+// ScheduledEvent는 SendAfter, SendAt으로 예약된 이벤트의 핸들이다.
+type ScheduledEvent[T any] struct {
+	loop  *Eventloop[T]
+	event T
+	at    time.Time
+	timer *time.Timer
+}
+
+func (s *ScheduledEvent[T]) At() time.Time {
+	return s.at
+}
+
+// Cancel은 아직 전달되지 않은 이벤트의 예약을 취소한다. 이미 전달되었거나 취소되었으면 false를 반환한다.
+func (s *ScheduledEvent[T]) Cancel() bool {
+	e := s.loop
+	if !e.unschedule(s) {
+		return false
+	}
+
+	s.timer.Stop()
+	e.leave()
+	return true
+}
+
+func (e *Eventloop[T]) SendAfter(event T, delay time.Duration) (*ScheduledEvent[T], error) {
+	return e.SendAt(event, time.Now().Add(delay))
+}
+
+// SendAt은 at 시각에 이벤트를 queue에 넣도록 예약한다.
+// 예약된 이벤트는 전달되거나 취소될 때까지 진행 중인 Send로 취급된다.
+func (e *Eventloop[T]) SendAt(event T, at time.Time) (*ScheduledEvent[T], error) {
+	e.state.Add(1)
+	if e.closed.Load() {
+		e.leave()
+		e.rejected.Add(1)
+		return nil, ErrAlreadyClosedLoop
+	}
+
+	s := &ScheduledEvent[T]{
+		loop:  e,
+		event: event,
+		at:    at,
+	}
+
+	e.schedMu.Lock()
+	// Close가 예약 목록을 정리한 뒤에 추가되지 않도록 잠금 안에서 다시 확인
+	if e.closed.Load() {
+		e.schedMu.Unlock()
+		e.leave()
+		e.rejected.Add(1)
+		return nil, ErrAlreadyClosedLoop
+	}
+	e.scheduled[s] = struct{}{}
+	e.scheduledCount.Add(1)
+	s.timer = time.AfterFunc(time.Until(at), func() {
+		if e.unschedule(s) {
+			e.deliver(s.event)
+			e.leave()
+		}
+	})
+	e.schedMu.Unlock()
+	e.sent.Add(1)
+	return s, nil
+}
+
+func (e *Eventloop[T]) unschedule(s *ScheduledEvent[T]) bool {
+	e.schedMu.Lock()
+	defer e.schedMu.Unlock()
+
+	if _, ok := e.scheduled[s]; !ok {
+		return false
+	}
+	delete(e.scheduled, s)
+	e.scheduledCount.Add(-1)
+	return true
+}
+
+// deliver는 Close 여부와 관계없이 예약된 이벤트를 Send와 같은 경로로 queue에 넣는다.
+// WithSpill을 사용하면 디스크에 남은 이벤트 뒤에 기록되고, 아니면 queue가 가득 찼을 때 overflow 정책을 따른다.
+// OverflowBlock이면 자리가 날 때까지 timer goroutine이 대기한다.
+// ForceClose되면 queue 대신 unsent에 보관하여 ForceClose가 반환하도록 한다.
+func (e *Eventloop[T]) deliver(event T) {
+	env := e.wrap(context.Background(), event)
+	if e.spill != nil {
+		err := e.sendSpill(env)
+		if errors.Is(err, ErrAlreadyClosedLoop) {
+			e.keepUnsent(env)
+		} else if err != nil {
+			e.drop(env, err)
+		}
+		return
+	}
+
+	if e.push(env) {
+		return
+	}
+	if ok, _ := e.applyOverflow(env); ok {
+		return
+	}
+
+	for {
+		select {
+		case e.pushCh <- env:
+			return
+		case <-e.notFull:
+			if e.push(env) {
+				return
+			}
+		case <-e.forceCh:
+			e.keepUnsent(env)
+			return
+		}
+	}
+}
+
+func (e *Eventloop[T]) keepUnsent(env Envelope[T]) {
+	e.schedMu.Lock()
+	defer e.schedMu.Unlock()
+	e.unsent = append(e.unsent, env)
+}
+
+// takeScheduled는 예약된 이벤트를 모두 취소하고 예약 시각 순서대로 반환한다.
+// 반환된 이벤트는 여전히 진행 중인 Send로 취급되므로 호출한 쪽에서 leave를 호출해야 한다.
+func (e *Eventloop[T]) takeScheduled() []*ScheduledEvent[T] {
+	e.schedMu.Lock()
+	defer e.schedMu.Unlock()
+
+	scheduled := make([]*ScheduledEvent[T], 0, len(e.scheduled))
+	for s := range e.scheduled {
+		s.timer.Stop()
+		scheduled = append(scheduled, s)
+	}
+	clear(e.scheduled)
+	e.scheduledCount.Store(0)
+
+	slices.SortFunc(scheduled, func(a, b *ScheduledEvent[T]) int {
+		return a.at.Compare(b.at)
+	})
+	return scheduled
+}
+
+// closeScheduled는 Close 시 ScheduleClosePolicy에 따라 예약된 이벤트를 정리한다.
+func (e *Eventloop[T]) closeScheduled() {
+	switch e.schedulePolicy {
+	case ScheduleFire:
+		scheduled := e.takeScheduled()
+		if len(scheduled) == 0 {
+			return
+		}
+		go func() {
+			for _, s := range scheduled {
+				e.deliver(s.event)
+				e.leave()
+			}
+		}()
+	case ScheduleDrop:
+		for _, s := range e.takeScheduled() {
+			e.drop(Envelope[T]{event: s.event, ctx: context.Background()}, ErrAlreadyClosedLoop)
+			e.leave()
+		}
+	}
+}
+
+// abortScheduled는 ForceClose 시 예약된 이벤트를 모두 취소하고 unsent에 보관한다.
+func (e *Eventloop[T]) abortScheduled() {
+	for _, s := range e.takeScheduled() {
+		e.keepUnsent(Envelope[T]{event: s.event, ctx: context.Background()})
+		e.leave()
+	}
+}
+
+// scheduledLen은 Stats가 schedMu를 잡지 않도록 별도로 관리하는 개수를 반환한다.
+func (e *Eventloop[T]) scheduledLen() int {
+	return int(e.scheduledCount.Load())
+}
@@ -0,0 +1,158 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recorder[T any] struct {
+	mu     sync.Mutex
+	events []T
+}
+
+func (r *recorder[T]) handle(event T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder[T]) get() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]T(nil), r.events...)
+}
+
+func TestEventloopSendAfter(t *testing.T) {
+	r := &recorder[int]{}
+	el := NewEventloop(1, 16, r.handle)
+	go el.Run()
+
+	start := time.Now()
+	_, err := el.SendAfter(1, 20*time.Millisecond)
+	require.NoError(t, err)
+	s, err := el.SendAt(2, start.Add(10*time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, start.Add(10*time.Millisecond), s.At())
+	canceled, err := el.SendAfter(3, 5*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 3, el.Stats().Scheduled)
+
+	require.True(t, canceled.Cancel())
+	require.False(t, canceled.Cancel())
+
+	require.Eventually(t, func() bool {
+		return len(r.get()) == 2
+	}, time.Second, time.Millisecond)
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	require.Equal(t, []int{2, 1}, r.get())
+	require.False(t, s.Cancel())
+	require.Equal(t, 0, el.Stats().Scheduled)
+
+	require.NoError(t, el.Shutdown(context.Background()))
+	_, err = el.SendAfter(4, time.Millisecond)
+	require.ErrorIs(t, err, ErrAlreadyClosedLoop)
+}
+
+func TestEventloopScheduleClosePolicy(t *testing.T) {
+	t.Run("wait", func(t *testing.T) {
+		r := &recorder[int]{}
+		el := NewEventloop(1, 16, r.handle)
+		go el.Run()
+
+		start := time.Now()
+		_, err := el.SendAfter(1, 20*time.Millisecond)
+		require.NoError(t, err)
+
+		require.NoError(t, el.Shutdown(context.Background()))
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		require.Equal(t, []int{1}, r.get())
+	})
+
+	t.Run("fire", func(t *testing.T) {
+		r := &recorder[int]{}
+		el := NewEventloop(1, 16, r.handle, WithScheduleClosePolicy[int](ScheduleFire))
+		go el.Run()
+
+		_, err := el.SendAfter(1, time.Hour)
+		require.NoError(t, err)
+		_, err = el.SendAfter(2, time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, el.Shutdown(context.Background()))
+		require.Equal(t, []int{2, 1}, r.get())
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		r := &recorder[int]{}
+		el := NewEventloop(1, 16, r.handle, WithScheduleClosePolicy[int](ScheduleDrop))
+		go el.Run()
+
+		_, err := el.SendAfter(1, time.Hour)
+		require.NoError(t, err)
+
+		require.NoError(t, el.Shutdown(context.Background()))
+		require.Empty(t, r.get())
+		require.Equal(t, uint64(1), el.Stats().Dropped)
+	})
+}
+
+func TestEventloopForceCloseScheduled(t *testing.T) {
+	r := &recorder[int]{}
+	el := NewEventloop(1, 16, r.handle)
+
+	_, err := el.SendAfter(3, time.Hour)
+	require.NoError(t, err)
+	_, err = el.SendAfter(2, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, el.Send(1))
+
+	require.Equal(t, []int{1, 2, 3}, el.ForceClose())
+	require.Equal(t, 0, el.Stats().Scheduled)
+}
+
+func TestEventloopScheduledOverflow(t *testing.T) {
+	r := &recorder[int]{}
+	el := NewEventloop(1, 1, r.handle, WithOverflowPolicy[int](OverflowDropNewest))
+	el.Pause()
+
+	require.NoError(t, el.Send(1))
+	_, err := el.SendAfter(2, time.Millisecond)
+	require.NoError(t, err)
+
+	// queue가 가득 차 있으므로 예약된 이벤트는 대기하지 않고 버려져야 함
+	require.Eventually(t, func() bool {
+		return el.Stats().Dropped == 1
+	}, time.Second, time.Millisecond)
+	require.Equal(t, 0, el.Stats().Scheduled)
+
+	el.Resume()
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{1}, r.get())
+}
+
+func TestEventloopScheduledSpill(t *testing.T) {
+	r := &recorder[int]{}
+	el := NewEventloop(1, 1, r.handle, WithSpill(t.TempDir(), JSONCodec[int]{}))
+	el.Pause()
+
+	for i := range 3 {
+		require.NoError(t, el.Send(i))
+	}
+	_, err := el.SendAfter(3, time.Millisecond)
+	require.NoError(t, err)
+
+	// 예약된 이벤트는 디스크에 남은 이벤트 뒤에 기록되어야 함
+	require.Eventually(t, func() bool {
+		return el.Stats().Spilled == 3
+	}, time.Second, time.Millisecond)
+
+	el.Resume()
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{0, 1, 2, 3}, r.get())
+}
@@ -0,0 +1,255 @@
+package ds
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const spillSegmentSize = 16 << 20
+
+// spillQueue는 queue가 가득 찼을 때 이벤트를 임시 파일에 기록하는 FIFO다.
+// 디스크에 이벤트가 남아 있는 동안에는 새 이벤트도 디스크에 기록하여 순서를 유지한다.
+type spillQueue[T any] struct {
+	dir     string
+	codec   Codec[T]
+	segSize int64
+
+	mu sync.Mutex
+	// files는 오래된 순서이며 마지막 파일에 기록한다.
+	files []string
+	w     *os.File
+	wSize int64
+	r     *os.File
+	rb    *bufio.Reader
+	// head는 디스크에서 읽었지만 queue에 자리가 없어 넣지 못한 이벤트다.
+	head  *Envelope[T]
+	count atomic.Int64
+}
+
+// WithSpill은 queue가 가득 차면 이벤트를 dir의 임시 파일에 기록하여 Send가 대기하지 않도록 한다.
+// 기록된 이벤트는 queue에 자리가 나면 들어온 순서대로 다시 읽어 처리한다.
+// dir이 비어 있으면 os.TempDir()을 사용하며 overflow 정책은 적용되지 않는다.
+// 디스크를 거친 이벤트에는 Tracer의 OnEnqueue가 반환한 context가 유지되지 않는다.
+func WithSpill[T any](dir string, codec Codec[T]) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		e.spill = &spillQueue[T]{dir: dir, codec: codec, segSize: spillSegmentSize}
+	}
+}
+
+// sendSpill은 디스크가 비어 있고 queue에 자리가 있으면 queue에, 아니면 디스크에 넣는다.
+func (e *Eventloop[T]) sendSpill(env Envelope[T]) error {
+	s := e.spill
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// ForceClose가 디스크를 비운 뒤에 기록하지 않도록 함
+	if e.forceClosed.Load() {
+		return ErrAlreadyClosedLoop
+	}
+
+	if s.count.Load() == 0 && e.push(env) {
+		return nil
+	}
+
+	if err := s.write(env); err != nil {
+		return err
+	}
+	// 디스크에 기록된 이벤트는 더 이상 합칠 수 없음
+	if env.slot != nil {
+		e.coalesce.abort(env.slot)
+	}
+	// 디스크에 이벤트가 남아 있는 동안 Close가 완료되지 않도록 state를 올려 둠
+	if s.count.Add(1) == 1 {
+		e.state.Add(1)
+	}
+	// 기록하는 동안 dispatcher가 queue를 비우고 대기 중일 수 있으므로 바로 옮겨서 깨움
+	e.refillLocked()
+	return nil
+}
+
+// refill은 queue에 자리가 있는 만큼 디스크의 이벤트를 옮긴다.
+// count는 s.mu를 잡은 상태에서만 확인해야 sendSpill이 기록 중인 이벤트를 놓치지 않는다.
+func (e *Eventloop[T]) refill() {
+	s := e.spill
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.refillLocked()
+}
+
+// refillLocked는 s.mu를 잡은 상태에서 호출해야 한다.
+func (e *Eventloop[T]) refillLocked() {
+	s := e.spill
+	for s.count.Load() > 0 {
+		if s.head == nil {
+			env, err := s.read()
+			if err != nil {
+				// 읽을 수 없는 이벤트는 모두 버림
+				e.dropped.Add(uint64(s.count.Load()))
+				s.count.Store(0)
+				s.reset()
+				e.leave()
+				return
+			}
+			s.head = &env
+		}
+
+		if !e.push(*s.head) {
+			return
+		}
+		s.head = nil
+		if s.count.Add(-1) == 0 {
+			s.reset()
+			e.leave()
+		}
+	}
+}
+
+// takeSpill은 ForceClose에서 디스크에 남은 이벤트를 모두 꺼낸다.
+func (e *Eventloop[T]) takeSpill() []Envelope[T] {
+	s := e.spill
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count.Load() == 0 {
+		return nil
+	}
+
+	var envs []Envelope[T]
+	if s.head != nil {
+		envs = append(envs, *s.head)
+		s.head = nil
+	}
+	for len(envs) < int(s.count.Load()) {
+		env, err := s.read()
+		if err != nil {
+			e.dropped.Add(uint64(int(s.count.Load()) - len(envs)))
+			break
+		}
+		envs = append(envs, env)
+	}
+	s.count.Store(0)
+	s.reset()
+	e.leave()
+	return envs
+}
+
+func (s *spillQueue[T]) write(env Envelope[T]) error {
+	payload, err := s.codec.Encode(env.event)
+	if err != nil {
+		return err
+	}
+
+	if s.w == nil || s.wSize >= s.segSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	// WAL의 레코드 형식을 사용하며 seq에는 WAL의 seq를 기록함
+	n, err := s.w.Write(encodeWALRecord(env.seq, payload))
+	s.wSize += int64(n)
+	return err
+}
+
+func (s *spillQueue[T]) rotate() error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, "eventloop-*.spill")
+	if err != nil {
+		return err
+	}
+	if s.w != nil {
+		s.w.Close()
+	}
+	s.w = f
+	s.wSize = 0
+	s.files = append(s.files, f.Name())
+	return nil
+}
+
+func (s *spillQueue[T]) read() (Envelope[T], error) {
+	for {
+		if s.r == nil {
+			if len(s.files) == 0 {
+				return Envelope[T]{}, io.ErrUnexpectedEOF
+			}
+			f, err := os.Open(s.files[0])
+			if err != nil {
+				return Envelope[T]{}, err
+			}
+			s.r = f
+			s.rb = bufio.NewReader(f)
+		}
+
+		header := make([]byte, walHeaderSize)
+		_, err := io.ReadFull(s.rb, header)
+		if errors.Is(err, io.EOF) && len(s.files) > 1 {
+			// 다 읽은 파일은 삭제하고 다음 파일로 넘어감
+			s.r.Close()
+			os.Remove(s.files[0])
+			s.files = s.files[1:]
+			s.r, s.rb = nil, nil
+			continue
+		}
+		if err != nil {
+			return Envelope[T]{}, err
+		}
+
+		size := int(binary.LittleEndian.Uint32(header))
+		record := make([]byte, walHeaderSize+size)
+		copy(record, header)
+		if _, err := io.ReadFull(s.rb, record[walHeaderSize:]); err != nil {
+			return Envelope[T]{}, err
+		}
+		seq, payload, _, ok := decodeWALRecord(record)
+		if !ok {
+			return Envelope[T]{}, ErrCorruptWAL
+		}
+		event, err := s.codec.Decode(payload)
+		if err != nil {
+			return Envelope[T]{}, err
+		}
+		// Tracer가 nil context를 받지 않도록 OnEnqueue의 context 대신 빈 context를 사용함
+		return Envelope[T]{event: event, ctx: context.Background(), seq: seq, deadline: deadlineOf(event)}, nil
+	}
+}
+
+// reset은 디스크가 비었을 때 모든 파일을 삭제한다.
+func (s *spillQueue[T]) reset() {
+	if s.r != nil {
+		s.r.Close()
+	}
+	if s.w != nil {
+		s.w.Close()
+	}
+	for _, name := range s.files {
+		os.Remove(name)
+	}
+	s.files = nil
+	s.w, s.wSize = nil, 0
+	s.r, s.rb = nil, nil
+	s.head = nil
+}
+
+func (e *Eventloop[T]) spilledLen() int {
+	if e.spill == nil {
+		return 0
+	}
+	return int(e.spill.count.Load())
+}
@@ -0,0 +1,152 @@
+package ds
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestSpill(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dir := t.TempDir()
+	block := make(chan struct{})
+	r := &recorder[int]{}
+	el := NewEventloop(1, 4, func(event int) {
+		<-block
+		r.handle(event)
+	}, WithSpill(dir, JSONCodec[int]{}))
+	el.spill.segSize = 256
+	go el.Run()
+
+	for i := range 500 {
+		require.NoError(t, el.TrySend(i))
+	}
+	stats := el.Stats()
+	require.Greater(t, stats.Spilled, 400)
+	require.Equal(t, uint64(500), stats.Sent)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Greater(t, len(files), 1)
+
+	close(block)
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	want := make([]int, 500)
+	for i := range want {
+		want[i] = i
+	}
+	require.Equal(t, want, r.get())
+	require.Zero(t, el.Stats().Spilled)
+	files, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestSpillInterleaved(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	r := &recorder[int]{}
+	el := NewEventloop(1, 2, func(event int) {
+		if event%50 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		r.handle(event)
+	}, WithSpill(t.TempDir(), JSONCodec[int]{}))
+	go el.Run()
+
+	want := make([]int, 1000)
+	for i := range want {
+		want[i] = i
+		require.NoError(t, el.Send(i))
+	}
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, want, r.get())
+}
+
+func TestSpillTracer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	tracer := NewRecordingTracer[int]()
+	r := &recorder[int]{}
+	el := NewEventloop(1, 1, r.handle, WithSpill(t.TempDir(), JSONCodec[int]{}), WithTracer[int](tracer))
+	for i := range 5 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Equal(t, 4, el.Stats().Spilled)
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, r.get())
+
+	ends := 0
+	for _, rec := range tracer.Records() {
+		if rec.Kind == TraceHandlerEnd {
+			ends++
+		}
+	}
+	require.Equal(t, 5, ends)
+}
+
+func TestSpillForceClose(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dir := t.TempDir()
+	el := NewEventloop(1, 2, func(int) {}, WithSpill(dir, JSONCodec[int]{}))
+	for i := range 10 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Equal(t, 8, el.Stats().Spilled)
+
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, el.ForceClose())
+	require.ErrorIs(t, el.Send(10), ErrAlreadyClosedLoop)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+// slowCodec은 디스크 기록 중에 dispatcher가 queue를 비우는 경쟁 구간을 넓힌다.
+type slowCodec[T any] struct {
+	JSONCodec[T]
+}
+
+func (c slowCodec[T]) Encode(event T) ([]byte, error) {
+	time.Sleep(10 * time.Microsecond)
+	return c.JSONCodec.Encode(event)
+}
+
+func TestSpillConcurrentSenders(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	const senders, perSender = 8, 50
+	for range 5 {
+		var handled atomic.Int64
+		el := NewEventloop(1, 1, func(int) {
+			handled.Add(1)
+		}, WithSpill(t.TempDir(), slowCodec[int]{}))
+		go el.Run()
+
+		wg := sync.WaitGroup{}
+		for range senders {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range perSender {
+					require.NoError(t, el.Send(i))
+				}
+			}()
+		}
+		wg.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		require.NoError(t, el.Shutdown(ctx), "handled=%d spilled=%d", handled.Load(), el.Stats().Spilled)
+		cancel()
+		require.Equal(t, int64(senders*perSender), handled.Load())
+	}
+}
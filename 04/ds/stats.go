@@ -0,0 +1,108 @@
+package ds
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+type EventloopStats struct {
+	QueueLen    int
+	QueueCap    int
+	InFlight    int
+	Dispatchers int
+	Paused      bool
+	Scheduled   int
+	// Spilled는 queue가 가득 차서 디스크에 기록된 이벤트 수다.
+	Spilled int
+	// Sent는 Send, SendAt 등에 성공한 이벤트 수다.
+	Sent    uint64
+	Handled uint64
+	// Rejected는 종료, 대기 시간 초과 등의 에러로 Send가 거절한 이벤트 수다.
+	Rejected uint64
+	// Dropped는 overflow 정책이나 예약 취소 정책으로 버려진 이벤트 수다.
+	Dropped uint64
+	// Coalesced는 WithCoalesce로 queue에서 대기 중인 이벤트와 합쳐진 이벤트 수다.
+	Coalesced uint64
+	// Expired는 deadline이 지나 handler를 호출하지 않고 버린 이벤트 수다.
+	Expired uint64
+	// DeadLetterFailed는 WithDeadLetter의 sink가 에러를 반환한 이벤트 수다.
+	DeadLetterFailed uint64
+	Latency          LatencyStats
+}
+
+// LatencyStats는 handler 실행 시간의 히스토그램이다.
+// Counts[i]는 Bounds[i] 이하인 실행 수이며 마지막 원소는 모든 Bounds를 넘은 실행 수다.
+// batch 모드에서는 handler 호출 한 번을 하나의 실행으로 본다.
+type LatencyStats struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+var latencyBounds = [...]time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+type latencyHistogram struct {
+	counts [len(latencyBounds) + 1]atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *latencyHistogram) snapshot() LatencyStats {
+	s := LatencyStats{
+		Bounds: slices.Clone(latencyBounds[:]),
+		Counts: make([]uint64, len(h.counts)),
+		Count:  h.count.Load(),
+		Sum:    time.Duration(h.sum.Load()),
+	}
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// Stats는 lock을 잡지 않고 atomic 값만 읽으므로 처리 중에도 자주 호출할 수 있다.
+func (e *Eventloop[T]) Stats() EventloopStats {
+	return EventloopStats{
+		QueueLen:         e.queue.Len(),
+		QueueCap:         e.queue.Cap(),
+		InFlight:         int(e.inFlight.Load()),
+		Dispatchers:      int(e.active.Load()),
+		Paused:           e.paused.Load(),
+		Scheduled:        e.scheduledLen(),
+		Spilled:          e.spilledLen(),
+		Sent:             e.sent.Load(),
+		Handled:          e.handled.Load(),
+		Rejected:         e.rejected.Load(),
+		Dropped:          e.dropped.Load(),
+		Coalesced:        e.coalesced.Load(),
+		Expired:          e.expired.Load(),
+		DeadLetterFailed: e.deadFailed.Load(),
+		Latency:          e.latency.snapshot(),
+	}
+}
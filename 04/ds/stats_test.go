@@ -0,0 +1,100 @@
+package ds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventloopStats(t *testing.T) {
+	block := make(chan struct{})
+	handler := func(event int) {
+		if event == 0 {
+			<-block
+		}
+	}
+	el := NewEventloop(1, 2, handler, WithOverflowPolicy[int](OverflowDropNewest))
+	go el.Run()
+
+	require.NoError(t, el.Send(0))
+	require.Eventually(t, func() bool {
+		return el.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(2))
+	require.ErrorIs(t, el.Send(3), ErrQueueFull)
+	require.ErrorIs(t, el.TrySend(4), ErrQueueFull)
+	require.ErrorIs(t, el.SendTimeout(5, time.Millisecond), ErrQueueFull)
+
+	stats := el.Stats()
+	require.Equal(t, 2, stats.QueueLen)
+	require.Equal(t, 2, stats.QueueCap)
+	require.Equal(t, 1, stats.InFlight)
+	require.Equal(t, 1, stats.Dispatchers)
+	require.Equal(t, uint64(3), stats.Sent)
+	require.Equal(t, uint64(0), stats.Handled)
+	require.Equal(t, uint64(1), stats.Rejected)
+	require.Equal(t, uint64(2), stats.Dropped)
+
+	close(block)
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.ErrorIs(t, el.Send(6), ErrAlreadyClosedLoop)
+
+	stats = el.Stats()
+	require.Equal(t, 0, stats.InFlight)
+	require.Equal(t, uint64(3), stats.Handled)
+	require.Equal(t, uint64(2), stats.Rejected)
+	require.Equal(t, uint64(3), stats.Latency.Count)
+	require.Len(t, stats.Latency.Counts, len(stats.Latency.Bounds)+1)
+
+	var total uint64
+	for _, c := range stats.Latency.Counts {
+		total += c
+	}
+	require.Equal(t, uint64(3), total)
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	h.observe(5 * time.Microsecond)
+	h.observe(10 * time.Microsecond)
+	h.observe(2 * time.Millisecond)
+	h.observe(time.Minute)
+
+	s := h.snapshot()
+	require.Equal(t, uint64(2), s.Counts[0])
+	require.Equal(t, uint64(1), s.Counts[5])
+	require.Equal(t, uint64(1), s.Counts[len(s.Counts)-1])
+	require.Equal(t, uint64(4), s.Count)
+	require.Equal(t, time.Minute+2*time.Millisecond+15*time.Microsecond, s.Sum)
+}
+
+func TestEventloopStatsLockFree(t *testing.T) {
+	el := NewPriorityEventloop(1, 4, 2, func(event int) {})
+	_, err := el.SendAfter(1, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, el.SendPriority(2, 1))
+
+	// 예약 목록과 우선순위 queue의 lock을 잡고 있어도 Stats는 대기하지 않아야 함
+	el.schedMu.Lock()
+	el.queue.(*priorityQueue[int]).mu.Lock()
+	done := make(chan EventloopStats)
+	go func() {
+		done <- el.Stats()
+	}()
+	select {
+	case stats := <-done:
+		require.Equal(t, 1, stats.Scheduled)
+		require.Equal(t, 1, stats.QueueLen)
+	case <-time.After(time.Second):
+		require.Fail(t, "Stats blocked on a lock")
+	}
+	el.queue.(*priorityQueue[int]).mu.Unlock()
+	el.schedMu.Unlock()
+
+	require.Equal(t, []int{2, 1}, el.ForceClose())
+	require.Zero(t, el.Stats().Scheduled)
+}
@@ -0,0 +1,160 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tracer는 이벤트의 생애주기마다 호출된다.
+// OnEnqueue가 반환한 context는 이벤트와 함께 queue를 지나 OnDequeue, OnHandlerStart로 전달되며
+// OnHandlerStart가 반환한 context는 NewEventloopContext의 handler와 OnHandlerEnd로 전달된다.
+// queue에 들어가지 못했거나 처리되지 못하고 버려진 이벤트는 OnDrop으로 전달된다.
+type Tracer[T any] interface {
+	OnEnqueue(ctx context.Context, event T) context.Context
+	OnDequeue(ctx context.Context, event T)
+	OnHandlerStart(ctx context.Context, event T) context.Context
+	OnHandlerEnd(ctx context.Context, event T)
+	OnDrop(ctx context.Context, event T, err error)
+	OnClose(force bool)
+}
+
+func WithTracer[T any](tracer Tracer[T]) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.tracer = tracer
+	}
+}
+
+// NopTracer는 아무것도 하지 않는 Tracer다. 일부 hook만 구현할 때 임베딩하여 사용한다.
+type NopTracer[T any] struct{}
+
+func (NopTracer[T]) OnEnqueue(ctx context.Context, event T) context.Context {
+	return ctx
+}
+
+func (NopTracer[T]) OnDequeue(ctx context.Context, event T) {}
+
+func (NopTracer[T]) OnHandlerStart(ctx context.Context, event T) context.Context {
+	return ctx
+}
+
+func (NopTracer[T]) OnHandlerEnd(ctx context.Context, event T) {}
+
+func (NopTracer[T]) OnDrop(ctx context.Context, event T, err error) {}
+
+func (NopTracer[T]) OnClose(force bool) {}
+
+type TraceKind int
+
+const (
+	TraceEnqueue TraceKind = iota
+	TraceDequeue
+	TraceHandlerStart
+	TraceHandlerEnd
+	TraceDrop
+	TraceClose
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceEnqueue:
+		return "enqueue"
+	case TraceDequeue:
+		return "dequeue"
+	case TraceHandlerStart:
+		return "handler_start"
+	case TraceHandlerEnd:
+		return "handler_end"
+	case TraceDrop:
+		return "drop"
+	case TraceClose:
+		return "close"
+	}
+	return "unknown"
+}
+
+// TraceRecord의 Span은 OnEnqueue에서 부여한 번호로, 같은 이벤트의 기록을 묶는 데 사용한다.
+type TraceRecord[T any] struct {
+	Kind  TraceKind
+	Span  uint64
+	Event T
+	Err   error
+	Force bool
+	Time  time.Time
+}
+
+type spanKey struct{}
+
+// RecordingTracer는 모든 hook 호출을 메모리에 기록하는 테스트용 Tracer다.
+type RecordingTracer[T any] struct {
+	mu      sync.Mutex
+	records []TraceRecord[T]
+	span    atomic.Uint64
+}
+
+func NewRecordingTracer[T any]() *RecordingTracer[T] {
+	return &RecordingTracer[T]{}
+}
+
+func (r *RecordingTracer[T]) record(ctx context.Context, kind TraceKind, event T, err error) {
+	span, _ := ctx.Value(spanKey{}).(uint64)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, TraceRecord[T]{
+		Kind:  kind,
+		Span:  span,
+		Event: event,
+		Err:   err,
+		Time:  time.Now(),
+	})
+}
+
+func (r *RecordingTracer[T]) OnEnqueue(ctx context.Context, event T) context.Context {
+	ctx = context.WithValue(ctx, spanKey{}, r.span.Add(1))
+	r.record(ctx, TraceEnqueue, event, nil)
+	return ctx
+}
+
+func (r *RecordingTracer[T]) OnDequeue(ctx context.Context, event T) {
+	r.record(ctx, TraceDequeue, event, nil)
+}
+
+func (r *RecordingTracer[T]) OnHandlerStart(ctx context.Context, event T) context.Context {
+	r.record(ctx, TraceHandlerStart, event, nil)
+	return ctx
+}
+
+func (r *RecordingTracer[T]) OnHandlerEnd(ctx context.Context, event T) {
+	r.record(ctx, TraceHandlerEnd, event, nil)
+}
+
+func (r *RecordingTracer[T]) OnDrop(ctx context.Context, event T, err error) {
+	r.record(ctx, TraceDrop, event, err)
+}
+
+func (r *RecordingTracer[T]) OnClose(force bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, TraceRecord[T]{Kind: TraceClose, Force: force, Time: time.Now()})
+}
+
+func (r *RecordingTracer[T]) Records() []TraceRecord[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]TraceRecord[T](nil), r.records...)
+}
+
+// Span은 span 번호로 기록된 hook의 순서를 반환한다.
+func (r *RecordingTracer[T]) Span(span uint64) []TraceKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var kinds []TraceKind
+	for _, record := range r.records {
+		if record.Span == span && record.Kind != TraceClose {
+			kinds = append(kinds, record.Kind)
+		}
+	}
+	return kinds
+}
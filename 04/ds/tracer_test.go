@@ -0,0 +1,145 @@
+package ds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventloopTracer(t *testing.T) {
+	tracer := NewRecordingTracer[int]()
+	handler := func(event int) {
+		time.Sleep(time.Millisecond)
+	}
+	el := NewEventloop(1, 1, handler, WithTracer[int](tracer), WithOverflowPolicy[int](OverflowDropNewest))
+
+	require.NoError(t, el.Send(1))
+	require.ErrorIs(t, el.Send(2), ErrQueueFull)
+
+	go el.Run()
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.ErrorIs(t, el.Send(3), ErrAlreadyClosedLoop)
+
+	require.Equal(t, []TraceKind{TraceEnqueue, TraceDequeue, TraceHandlerStart, TraceHandlerEnd}, tracer.Span(1))
+	require.Equal(t, []TraceKind{TraceEnqueue, TraceDrop}, tracer.Span(2))
+	require.Equal(t, []TraceKind{TraceEnqueue, TraceDrop}, tracer.Span(3))
+
+	times := make(map[TraceKind]time.Time)
+	var closeCount int
+	for _, r := range tracer.Records() {
+		switch {
+		case r.Kind == TraceClose:
+			closeCount++
+			require.False(t, r.Force)
+		case r.Span == 1:
+			times[r.Kind] = r.Time
+		case r.Span == 2:
+			if r.Kind == TraceDrop {
+				require.ErrorIs(t, r.Err, ErrQueueFull)
+			}
+		case r.Span == 3:
+			if r.Kind == TraceDrop {
+				require.ErrorIs(t, r.Err, ErrAlreadyClosedLoop)
+			}
+		}
+	}
+	require.Equal(t, 1, closeCount)
+	// queue 대기 시간과 handler 실행 시간을 나누어 측정할 수 있어야 함
+	require.GreaterOrEqual(t, times[TraceHandlerEnd].Sub(times[TraceHandlerStart]), time.Millisecond)
+	require.False(t, times[TraceDequeue].Before(times[TraceEnqueue]))
+}
+
+func TestEventloopTracerForceClose(t *testing.T) {
+	tracer := NewRecordingTracer[int]()
+	el := NewEventloop(1, 4, func(event int) {}, WithTracer[int](tracer))
+
+	require.NoError(t, el.Send(1))
+	require.Equal(t, []int{1}, el.ForceClose())
+
+	records := tracer.Records()
+	require.Len(t, records, 3)
+	require.Equal(t, TraceEnqueue, records[0].Kind)
+	require.Equal(t, TraceClose, records[1].Kind)
+	require.True(t, records[1].Force)
+	require.Equal(t, TraceDrop, records[2].Kind)
+	require.ErrorIs(t, records[2].Err, ErrAlreadyClosedLoop)
+}
+
+type traceIDKey struct{}
+
+type contextTracer struct {
+	NopTracer[int]
+	ids chan any
+}
+
+func (c *contextTracer) OnHandlerStart(ctx context.Context, event int) context.Context {
+	c.ids <- ctx.Value(traceIDKey{})
+	return ctx
+}
+
+func TestEventloopTracerContext(t *testing.T) {
+	tracer := &contextTracer{ids: make(chan any, 1)}
+	el := NewEventloop(1, 4, func(event int) {}, WithTracer[int](tracer))
+	go el.Run()
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "trace-1")
+	require.NoError(t, el.SendContext(ctx, 1))
+	require.Equal(t, "trace-1", <-tracer.ids)
+	el.Close()
+}
+
+type handlerContextTracer struct {
+	NopTracer[int]
+}
+
+func (handlerContextTracer) OnHandlerStart(ctx context.Context, event int) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, event)
+}
+
+func TestEventloopContextHandler(t *testing.T) {
+	ids := make(chan any, 2)
+	var middlewareCalls int
+	el := NewEventloopContext(1, 4, func(ctx context.Context, event int) {
+		ids <- ctx.Value(traceIDKey{})
+	},
+		WithTracer[int](handlerContextTracer{}),
+		WithMiddleware(func(next func(int)) func(int) {
+			return func(event int) {
+				middlewareCalls++
+				next(event)
+			}
+		}),
+	)
+	go el.Run()
+
+	require.NoError(t, el.Send(1))
+	require.NoError(t, el.Send(2))
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	// OnHandlerStart가 반환한 context가 middleware를 거쳐 handler에 전달되어야 함
+	require.Equal(t, 1, <-ids)
+	require.Equal(t, 2, <-ids)
+	require.Equal(t, 2, middlewareCalls)
+}
+
+func TestEventloopTracerRejected(t *testing.T) {
+	tracer := NewRecordingTracer[int]()
+	el := NewEventloop(1, 1, func(event int) {}, WithTracer[int](tracer))
+
+	require.NoError(t, el.Send(1))
+	require.ErrorIs(t, el.TrySend(2), ErrQueueFull)
+	require.ErrorIs(t, el.SendTimeout(3, time.Millisecond), ErrSendTimeout)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, el.SendContext(ctx, 4), context.Canceled)
+	el.Close()
+	require.ErrorIs(t, el.Send(5), ErrAlreadyClosedLoop)
+
+	// Send가 거절한 이벤트의 span은 모두 OnDrop으로 끝나야 함
+	for span := uint64(2); span <= 5; span++ {
+		require.Equal(t, []TraceKind{TraceEnqueue, TraceDrop}, tracer.Span(span))
+	}
+	require.Equal(t, []int{1}, el.ForceClose())
+}
@@ -0,0 +1,535 @@
+package ds
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Codec은 WAL에 기록할 이벤트를 직렬화한다.
+type Codec[T any] interface {
+	Encode(event T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(event T) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var event T
+	err := json.Unmarshal(data, &event)
+	return event, err
+}
+
+type SyncPolicy int
+
+const (
+	// SyncAlways는 기록할 때마다 fsync하므로 Send가 성공한 이벤트는 장애 후에도 남는다.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval은 WALConfig.Interval마다 fsync한다. 마지막 주기 이후의 이벤트는 유실될 수 있다.
+	SyncInterval
+	// SyncNever는 fsync하지 않고 OS에 맡긴다.
+	SyncNever
+)
+
+type WALConfig struct {
+	// SegmentSize를 넘으면 새 segment 파일을 만든다. 기본 64MiB.
+	SegmentSize int64
+	Sync        SyncPolicy
+	// Interval마다 checkpoint를 기록하고 처리가 끝난 segment를 삭제한다. 기본 100ms.
+	Interval time.Duration
+}
+
+// WAL은 Eventloop에 들어온 이벤트를 segment 파일에 기록하고 처리된 이벤트를 checkpoint한다.
+// 처리되지 않은 이벤트는 다음에 WithWAL로 생성한 Eventloop이 먼저 처리하므로 이벤트는 최소 한 번 처리된다.
+// 하나의 WAL은 하나의 Eventloop에서만 사용해야 한다.
+type WAL[T any] struct {
+	dir   string
+	codec Codec[T]
+	cfg   WALConfig
+
+	mu       sync.Mutex
+	seg      *os.File
+	segSize  int64
+	segments []uint64
+	next     uint64
+	// acked 미만의 seq는 모두 처리되었고 done은 acked 이상에서 처리된 seq다.
+	acked   uint64
+	done    map[uint64]struct{}
+	dirty   bool
+	changed bool
+	pending []walEntry[T]
+	closed  bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+type walEntry[T any] struct {
+	seq   uint64
+	event T
+}
+
+const (
+	walExt          = ".wal"
+	walCheckpoint   = "checkpoint"
+	walHeaderSize   = 16
+	walDefaultSize  = 64 << 20
+	walDefaultFlush = 100 * time.Millisecond
+)
+
+// OpenWAL은 dir의 WAL을 열고 처리되지 않은 이벤트를 읽어 둔다.
+// 마지막 segment 끝에 기록이 끊긴 레코드가 있으면 잘라낸다.
+func OpenWAL[T any](dir string, codec Codec[T], cfg WALConfig) (*WAL[T], error) {
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = walDefaultSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = walDefaultFlush
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := &WAL[T]{
+		dir:    dir,
+		codec:  codec,
+		cfg:    cfg,
+		next:   1,
+		acked:  1,
+		done:   make(map[uint64]struct{}),
+		stopCh: make(chan struct{}),
+	}
+	if err := w.readCheckpoint(); err != nil {
+		return nil, err
+	}
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	if err := w.compact(); err != nil {
+		w.seg.Close()
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.run()
+	}()
+	return w, nil
+}
+
+func (w *WAL[T]) load() error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, walExt) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(name, walExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		w.segments = append(w.segments, first)
+	}
+	slices.Sort(w.segments)
+
+	for i, first := range w.segments {
+		last := i == len(w.segments)-1
+		if err := w.readSegment(first, last); err != nil {
+			return err
+		}
+	}
+
+	w.next = max(w.next, w.acked)
+	if len(w.segments) == 0 {
+		return w.create(w.next)
+	}
+
+	active := w.segments[len(w.segments)-1]
+	f, err := os.OpenFile(w.path(active), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.seg = f
+	w.segSize = info.Size()
+	return nil
+}
+
+// readSegment는 segment의 레코드 중 처리되지 않은 것을 pending에 추가한다.
+func (w *WAL[T]) readSegment(first uint64, last bool) error {
+	f, err := os.OpenFile(w.path(first), os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	off := 0
+	for off < len(data) {
+		seq, payload, n, ok := decodeWALRecord(data[off:])
+		if !ok {
+			if !last {
+				return fmt.Errorf("%w: %s at offset %d", ErrCorruptWAL, w.path(first), off)
+			}
+			// 마지막 segment는 기록 도중 중단되었을 수 있으므로 잘라냄
+			return f.Truncate(int64(off))
+		}
+		off += n
+		w.next = max(w.next, seq+1)
+
+		if seq < w.acked {
+			continue
+		}
+		if _, ok := w.done[seq]; ok {
+			continue
+		}
+		event, err := w.codec.Decode(payload)
+		if err != nil {
+			return fmt.Errorf("%w: seq %d: %v", ErrCorruptWAL, seq, err)
+		}
+		w.pending = append(w.pending, walEntry[T]{seq: seq, event: event})
+	}
+	return nil
+}
+
+func (w *WAL[T]) path(first uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", first, walExt))
+}
+
+func (w *WAL[T]) create(first uint64) error {
+	f, err := os.OpenFile(w.path(first), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.seg = f
+	w.segSize = 0
+	w.segments = append(w.segments, first)
+	return nil
+}
+
+// unacked는 OpenWAL에서 읽은 처리되지 않은 이벤트를 seq 순서로 한 번만 반환한다.
+func (w *WAL[T]) unacked() []walEntry[T] {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pending := w.pending
+	w.pending = nil
+	return pending
+}
+
+// append는 이벤트를 기록하고 seq를 반환한다.
+func (w *WAL[T]) append(event T) (uint64, error) {
+	payload, err := w.codec.Encode(event)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	seq := w.next
+	if w.segSize > 0 && w.segSize+int64(walHeaderSize+len(payload)) > w.cfg.SegmentSize {
+		if err := w.rotate(seq); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.seg.Write(encodeWALRecord(seq, payload))
+	w.segSize += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	w.next++
+
+	if w.cfg.Sync == SyncAlways {
+		if err := w.seg.Sync(); err != nil {
+			return 0, err
+		}
+	} else {
+		w.dirty = true
+	}
+	return seq, nil
+}
+
+func (w *WAL[T]) rotate(first uint64) error {
+	if err := w.sync(); err != nil {
+		return err
+	}
+	if err := w.seg.Close(); err != nil {
+		return err
+	}
+	return w.create(first)
+}
+
+// ack는 seq의 이벤트가 처리되었음을 기록한다. 디스크에는 다음 checkpoint에 반영된다.
+func (w *WAL[T]) ack(seq uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if seq < w.acked {
+		return
+	}
+
+	w.done[seq] = struct{}{}
+	for {
+		if _, ok := w.done[w.acked]; !ok {
+			break
+		}
+		delete(w.done, w.acked)
+		w.acked++
+	}
+	w.changed = true
+}
+
+func (w *WAL[T]) run() {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Checkpoint()
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// Checkpoint는 처리된 이벤트를 기록하고 모든 이벤트가 처리된 segment를 삭제한다.
+// SyncInterval이면 segment도 fsync한다.
+func (w *WAL[T]) Checkpoint() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.checkpoint()
+}
+
+func (w *WAL[T]) checkpoint() error {
+	if w.cfg.Sync == SyncInterval {
+		if err := w.sync(); err != nil {
+			return err
+		}
+	}
+	if !w.changed {
+		return nil
+	}
+	if err := w.writeCheckpoint(); err != nil {
+		return err
+	}
+	w.changed = false
+	return w.compact()
+}
+
+func (w *WAL[T]) sync() error {
+	if !w.dirty || w.cfg.Sync == SyncNever {
+		return nil
+	}
+	w.dirty = false
+	return w.seg.Sync()
+}
+
+// compact는 다음 segment의 첫 seq가 acked 이하인, 즉 모든 이벤트가 처리된 segment를 삭제한다.
+func (w *WAL[T]) compact() error {
+	n := 0
+	for n < len(w.segments)-1 && w.segments[n+1] <= w.acked {
+		if err := os.Remove(w.path(w.segments[n])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		n++
+	}
+	w.segments = w.segments[n:]
+	return nil
+}
+
+// checkpoint 파일은 acked, done의 개수와 seq들, crc32로 구성된다.
+func (w *WAL[T]) writeCheckpoint() error {
+	buf := binary.LittleEndian.AppendUint64(nil, w.acked)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(w.done)))
+	for seq := range w.done {
+		buf = binary.LittleEndian.AppendUint64(buf, seq)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+
+	tmp := filepath.Join(w.dir, walCheckpoint+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if w.cfg.Sync != SyncNever {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(w.dir, walCheckpoint))
+}
+
+func (w *WAL[T]) readCheckpoint() error {
+	buf, err := os.ReadFile(filepath.Join(w.dir, walCheckpoint))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(buf) < 16 {
+		return fmt.Errorf("%w: short checkpoint", ErrCorruptWAL)
+	}
+	body, sum := buf[:len(buf)-4], binary.LittleEndian.Uint32(buf[len(buf)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checkpoint checksum mismatch", ErrCorruptWAL)
+	}
+
+	w.acked = binary.LittleEndian.Uint64(body)
+	n := int(binary.LittleEndian.Uint32(body[8:]))
+	if len(body) != 12+8*n {
+		return fmt.Errorf("%w: checkpoint length mismatch", ErrCorruptWAL)
+	}
+	for i := range n {
+		w.done[binary.LittleEndian.Uint64(body[12+8*i:])] = struct{}{}
+	}
+	return nil
+}
+
+// Close는 checkpoint를 기록하고 파일을 닫는다. Eventloop이 종료된 뒤에 호출해야 한다.
+func (w *WAL[T]) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stopCh)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := errors.Join(w.checkpoint(), w.sync())
+	return errors.Join(err, w.seg.Close())
+}
+
+// 레코드는 payload 길이, crc32, seq, payload 순서로 기록된다. crc32는 seq와 payload를 대상으로 한다.
+func encodeWALRecord(seq uint64, payload []byte) []byte {
+	buf := make([]byte, walHeaderSize, walHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint64(buf[8:], seq)
+	buf = append(buf, payload...)
+	binary.LittleEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(buf[8:]))
+	return buf
+}
+
+func decodeWALRecord(data []byte) (seq uint64, payload []byte, n int, ok bool) {
+	if len(data) < walHeaderSize {
+		return 0, nil, 0, false
+	}
+	size := int(binary.LittleEndian.Uint32(data[0:]))
+	n = walHeaderSize + size
+	if len(data) < n {
+		return 0, nil, 0, false
+	}
+	if crc32.ChecksumIEEE(data[8:n]) != binary.LittleEndian.Uint32(data[4:]) {
+		return 0, nil, 0, false
+	}
+	return binary.LittleEndian.Uint64(data[8:]), data[walHeaderSize:n], n, true
+}
+
+// WithWAL은 Send에 성공한 이벤트를 w에 기록하고 처리가 끝나면 checkpoint한다.
+// w에 처리되지 않은 이벤트가 남아 있으면 Eventloop은 queue보다 먼저 이 이벤트들을 seq 순서로 처리한다.
+// SendAfter, SendAt으로 예약한 이벤트는 기록되지 않는다.
+func WithWAL[T any](w *WAL[T]) EventloopOption[T] {
+	return func(e *Eventloop[T]) {
+		e.wal = w
+	}
+}
+
+// replayWAL은 재생할 이벤트가 남아 있는 동안 state를 올려 두어 Close가 재생을 기다리도록 한다.
+func (e *Eventloop[T]) replayWAL() {
+	for _, entry := range e.wal.unacked() {
+		env := e.wrap(context.Background(), entry.event)
+		env.seq = entry.seq
+		e.replay = append(e.replay, env)
+	}
+	if len(e.replay) > 0 {
+		e.state.Add(1)
+	}
+}
+
+func (e *Eventloop[T]) popReplay() (Envelope[T], bool) {
+	e.replayMu.Lock()
+	defer e.replayMu.Unlock()
+	if len(e.replay) == 0 {
+		return Envelope[T]{}, false
+	}
+
+	env := e.replay[0]
+	e.replay = e.replay[1:]
+	if len(e.replay) == 0 {
+		e.replay = nil
+		e.leave()
+	}
+	return env, true
+}
+
+func (e *Eventloop[T]) takeReplay() []Envelope[T] {
+	e.replayMu.Lock()
+	defer e.replayMu.Unlock()
+	envs := e.replay
+	e.replay = nil
+	if len(envs) > 0 {
+		e.leave()
+	}
+	return envs
+}
+
+func (e *Eventloop[T]) ack(env Envelope[T]) {
+	if e.wal == nil {
+		return
+	}
+	if env.seq != 0 {
+		e.wal.ack(env.seq)
+	}
+	if env.slot != nil {
+		for _, seq := range env.slot.seqs {
+			e.wal.ack(seq)
+		}
+	}
+}
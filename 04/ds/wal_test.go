@@ -0,0 +1,161 @@
+package ds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func walSegments(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+walExt))
+	require.NoError(t, err)
+	return files
+}
+
+func TestWALReplay(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+
+	block := make(chan struct{})
+	r := &recorder[int]{}
+	el := NewEventloop(1, 8, func(event int) {
+		if event == 0 {
+			<-block
+		}
+		r.handle(event)
+	}, WithWAL(w))
+	go el.Run()
+
+	for i := range 5 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Eventually(t, func() bool {
+		return el.Stats().InFlight == 1
+	}, time.Second, time.Millisecond)
+
+	// 0만 처리된 상태에서 강제 종료하여 장애를 흉내냄
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(block)
+	}()
+	require.Equal(t, []int{1, 2, 3, 4}, el.ForceClose())
+	<-el.Done()
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+	r = &recorder[int]{}
+	el = NewEventloop(2, 1, r.handle, WithWAL(w))
+	go el.Run()
+	require.NoError(t, el.Send(5))
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.NoError(t, w.Close())
+	require.ElementsMatch(t, []int{1, 2, 3, 4, 5}, r.get())
+
+	w, err = OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+	require.Empty(t, w.unacked())
+	require.NoError(t, w.Close())
+}
+
+func TestWALReplayOrder(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, JSONCodec[string]{}, WALConfig{Sync: SyncNever})
+	require.NoError(t, err)
+	for _, s := range []string{"a", "b", "c"} {
+		_, err := w.append(s)
+		require.NoError(t, err)
+	}
+	w.ack(2)
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, JSONCodec[string]{}, WALConfig{Sync: SyncNever})
+	require.NoError(t, err)
+	r := &recorder[string]{}
+	el := NewEventloop(1, 1, r.handle, WithWAL(w))
+	go el.Run()
+	require.NoError(t, el.Send("d"))
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.NoError(t, w.Close())
+	require.Equal(t, []string{"a", "c", "d"}, r.get())
+}
+
+func TestWALRejectedNotReplayed(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+
+	el := NewEventloop(1, 1, func(int) {}, WithWAL(w), WithOverflowPolicy[int](OverflowDropNewest))
+	require.NoError(t, el.Send(1))
+	require.ErrorIs(t, el.Send(2), ErrQueueFull)
+	require.ErrorIs(t, el.TrySend(3), ErrQueueFull)
+	require.Equal(t, []int{1}, el.ForceClose())
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+	defer w.Close()
+	require.Equal(t, []walEntry[int]{{seq: 1, event: 1}}, w.unacked())
+}
+
+func TestWALCompaction(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, JSONCodec[int]{}, WALConfig{SegmentSize: 64, Sync: SyncInterval, Interval: time.Hour})
+	require.NoError(t, err)
+
+	el := NewEventloop(4, 16, func(int) {}, WithWAL(w))
+	go el.Run()
+	for i := range 100 {
+		require.NoError(t, el.Send(i))
+	}
+	require.Greater(t, len(walSegments(t, dir)), 10)
+
+	require.NoError(t, el.Shutdown(context.Background()))
+	require.NoError(t, w.Checkpoint())
+	require.Len(t, walSegments(t, dir), 1)
+	require.NoError(t, w.Close())
+	require.ErrorIs(t, w.Checkpoint(), os.ErrClosed)
+}
+
+func TestWALTornTail(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+	for i := range 3 {
+		_, err := w.append(i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	segments := walSegments(t, dir)
+	require.Len(t, segments, 1)
+	f, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write(encodeWALRecord(4, []byte("100"))[:10])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	w, err = OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+	require.Equal(t, []walEntry[int]{{1, 0}, {2, 1}, {3, 2}}, w.unacked())
+	seq, err := w.append(3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), seq)
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, JSONCodec[int]{}, WALConfig{})
+	require.NoError(t, err)
+	require.Len(t, w.unacked(), 4)
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,230 @@
+package exporter
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"syncgo/ds"
+)
+
+type EventloopSource interface {
+	Stats() ds.EventloopStats
+}
+
+type MapSource interface {
+	Len() int
+}
+
+// Exporter는 이름을 붙여 등록한 Eventloop과 Map의 상태를 Prometheus text 형식과 expvar로 내보낸다.
+type Exporter struct {
+	namespace string
+	loops     *ds.Map[string, EventloopSource]
+	maps      *ds.Map[string, MapSource]
+}
+
+// New는 metric 이름 앞에 namespace를 붙이는 Exporter를 생성한다. namespace가 비어 있으면 syncgo를 사용한다.
+func New(namespace string) *Exporter {
+	if namespace == "" {
+		namespace = "syncgo"
+	}
+	return &Exporter{
+		namespace: namespace,
+		loops:     ds.NewMap[string, EventloopSource](8),
+		maps:      ds.NewMap[string, MapSource](8),
+	}
+}
+
+func (x *Exporter) RegisterEventloop(name string, el EventloopSource) {
+	x.loops.Store(name, el)
+}
+
+func (x *Exporter) RegisterMap(name string, m MapSource) {
+	x.maps.Store(name, m)
+}
+
+func (x *Exporter) Unregister(name string) {
+	x.loops.Delete(name)
+	x.maps.Delete(name)
+}
+
+type namedStats struct {
+	name  string
+	stats ds.EventloopStats
+}
+
+type namedSize struct {
+	name string
+	size int
+}
+
+func (x *Exporter) collect() ([]namedStats, []namedSize) {
+	var loops []namedStats
+	x.loops.Range(func(name string, el EventloopSource) bool {
+		loops = append(loops, namedStats{name: name, stats: el.Stats()})
+		return true
+	})
+	slices.SortFunc(loops, func(a, b namedStats) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	var maps []namedSize
+	x.maps.Range(func(name string, m MapSource) bool {
+		maps = append(maps, namedSize{name: name, size: m.Len()})
+		return true
+	})
+	slices.SortFunc(maps, func(a, b namedSize) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return loops, maps
+}
+
+// WritePrometheus는 Prometheus text exposition 형식으로 metric을 기록한다.
+func (x *Exporter) WritePrometheus(w io.Writer) error {
+	loops, maps := x.collect()
+	bw := bufio.NewWriter(w)
+
+	gauge := func(name, help string, value func(ds.EventloopStats) float64) {
+		x.header(bw, name, help, "gauge")
+		for _, l := range loops {
+			x.sample(bw, name, l.name, "", value(l.stats))
+		}
+	}
+	counter := func(name, help string, value func(ds.EventloopStats) uint64) {
+		x.header(bw, name, help, "counter")
+		for _, l := range loops {
+			x.sample(bw, name, l.name, "", float64(value(l.stats)))
+		}
+	}
+
+	if len(loops) > 0 {
+		gauge("eventloop_queue_length", "Number of events waiting in the queue.", func(s ds.EventloopStats) float64 {
+			return float64(s.QueueLen)
+		})
+		gauge("eventloop_queue_capacity", "Capacity of the queue.", func(s ds.EventloopStats) float64 {
+			return float64(s.QueueCap)
+		})
+		gauge("eventloop_in_flight", "Number of events being handled.", func(s ds.EventloopStats) float64 {
+			return float64(s.InFlight)
+		})
+		gauge("eventloop_dispatchers", "Number of running dispatchers.", func(s ds.EventloopStats) float64 {
+			return float64(s.Dispatchers)
+		})
+		gauge("eventloop_paused", "Whether the eventloop is paused.", func(s ds.EventloopStats) float64 {
+			if s.Paused {
+				return 1
+			}
+			return 0
+		})
+		gauge("eventloop_scheduled", "Number of scheduled events not yet delivered.", func(s ds.EventloopStats) float64 {
+			return float64(s.Scheduled)
+		})
+		gauge("eventloop_spilled", "Number of events spilled to disk.", func(s ds.EventloopStats) float64 {
+			return float64(s.Spilled)
+		})
+		counter("eventloop_sent_total", "Total number of accepted events.", func(s ds.EventloopStats) uint64 {
+			return s.Sent
+		})
+		counter("eventloop_handled_total", "Total number of handled events.", func(s ds.EventloopStats) uint64 {
+			return s.Handled
+		})
+		counter("eventloop_rejected_total", "Total number of rejected sends.", func(s ds.EventloopStats) uint64 {
+			return s.Rejected
+		})
+		counter("eventloop_dropped_total", "Total number of events dropped by policy.", func(s ds.EventloopStats) uint64 {
+			return s.Dropped
+		})
+		counter("eventloop_coalesced_total", "Total number of events merged into a pending event.", func(s ds.EventloopStats) uint64 {
+			return s.Coalesced
+		})
+		counter("eventloop_expired_total", "Total number of events skipped after their deadline.", func(s ds.EventloopStats) uint64 {
+			return s.Expired
+		})
+		counter("eventloop_dead_letter_failed_total", "Total number of dead letters the sink failed to accept.", func(s ds.EventloopStats) uint64 {
+			return s.DeadLetterFailed
+		})
+
+		name := "eventloop_handler_duration_seconds"
+		x.header(bw, name, "Handler latency.", "histogram")
+		for _, l := range loops {
+			latency := l.stats.Latency
+			var cumulative uint64
+			for i, bound := range latency.Bounds {
+				cumulative += latency.Counts[i]
+				x.sample(bw, name+"_bucket", l.name, formatFloat(bound.Seconds()), float64(cumulative))
+			}
+			x.sample(bw, name+"_bucket", l.name, "+Inf", float64(latency.Count))
+			x.sample(bw, name+"_sum", l.name, "", latency.Sum.Seconds())
+			x.sample(bw, name+"_count", l.name, "", float64(latency.Count))
+		}
+	}
+
+	if len(maps) > 0 {
+		x.header(bw, "map_size", "Number of entries in the map.", "gauge")
+		for _, m := range maps {
+			x.sample(bw, "map_size", m.name, "", float64(m.size))
+		}
+	}
+
+	return bw.Flush()
+}
+
+func (x *Exporter) header(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s_%s %s\n", x.namespace, name, help)
+	fmt.Fprintf(w, "# TYPE %s_%s %s\n", x.namespace, name, typ)
+}
+
+func (x *Exporter) sample(w io.Writer, name, label, le string, value float64) {
+	if le == "" {
+		fmt.Fprintf(w, "%s_%s{name=\"%s\"} %s\n", x.namespace, name, escape(label), formatFloat(value))
+		return
+	}
+	fmt.Fprintf(w, "%s_%s{name=\"%s\",le=\"%s\"} %s\n", x.namespace, name, escape(label), le, formatFloat(value))
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ServeHTTP는 /metrics 등에 등록하여 Prometheus가 수집할 수 있도록 한다.
+func (x *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	x.WritePrometheus(w)
+}
+
+// Expvar는 등록된 Eventloop의 Stats와 Map의 크기를 expvar 값으로 반환한다.
+func (x *Exporter) Expvar() expvar.Var {
+	return expvar.Func(func() any {
+		loops, maps := x.collect()
+		v := map[string]any{}
+
+		loopVars := make(map[string]ds.EventloopStats, len(loops))
+		for _, l := range loops {
+			loopVars[l.name] = l.stats
+		}
+		v["eventloops"] = loopVars
+
+		mapVars := make(map[string]int, len(maps))
+		for _, m := range maps {
+			mapVars[m.name] = m.size
+		}
+		v["maps"] = mapVars
+		return v
+	})
+}
+
+// PublishExpvar는 name으로 expvar에 등록한다. expvar.Publish와 같이 같은 이름으로 두 번 호출하면 panic이 발생한다.
+func (x *Exporter) PublishExpvar(name string) {
+	expvar.Publish(name, x.Expvar())
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"syncgo/ds"
+)
+
+func TestExporterPrometheus(t *testing.T) {
+	el := ds.NewEventloop(1, 8, func(event int) {})
+	go el.Run()
+	for i := range 3 {
+		require.NoError(t, el.Send(i))
+	}
+	require.NoError(t, el.Shutdown(context.Background()))
+
+	m := ds.NewMap[string, int](4)
+	m.Store("a", 1)
+	m.Store("b", 2)
+
+	x := New("")
+	x.RegisterEventloop(`orders"1`, el)
+	x.RegisterMap("sessions", m)
+
+	srv := httptest.NewServer(x)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	out := string(body)
+
+	require.Contains(t, out, "# TYPE syncgo_eventloop_queue_length gauge\n")
+	require.Contains(t, out, `syncgo_eventloop_queue_capacity{name="orders\"1"} 8`+"\n")
+	require.Contains(t, out, `syncgo_eventloop_sent_total{name="orders\"1"} 3`+"\n")
+	require.Contains(t, out, `syncgo_eventloop_handled_total{name="orders\"1"} 3`+"\n")
+	require.Contains(t, out, "# TYPE syncgo_eventloop_handler_duration_seconds histogram\n")
+	require.Contains(t, out, `syncgo_eventloop_handler_duration_seconds_bucket{name="orders\"1",le="+Inf"} 3`+"\n")
+	require.Contains(t, out, `syncgo_eventloop_handler_duration_seconds_count{name="orders\"1"} 3`+"\n")
+	require.Contains(t, out, `syncgo_map_size{name="sessions"} 2`+"\n")
+
+	x.Unregister("sessions")
+	var sb strings.Builder
+	require.NoError(t, x.WritePrometheus(&sb))
+	require.NotContains(t, sb.String(), "syncgo_map_size")
+}
+
+func TestExporterExpvar(t *testing.T) {
+	el := ds.NewEventloop(2, 4, func(event int) {})
+	m := ds.NewMap[int, int](4)
+	m.Store(1, 1)
+
+	x := New("app")
+	x.RegisterEventloop("jobs", el)
+	x.RegisterMap("cache", m)
+
+	var v struct {
+		Eventloops map[string]ds.EventloopStats `json:"eventloops"`
+		Maps       map[string]int               `json:"maps"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(x.Expvar().String()), &v))
+	require.Equal(t, 4, v.Eventloops["jobs"].QueueCap)
+	require.Equal(t, 1, v.Maps["cache"])
+}